@@ -1,7 +1,7 @@
 import './style.css';
 import htmlTemplate from './template.html?raw';
 
-import { SelectFolder, StartExecution, ShowResults, CancelExecution, CheckIfResultsExist, GetResults, RevealInExplorer, FullReset } from '../wailsjs/go/processing/FrontendApp';
+import { SelectFolder, StartExecution, ShowResults, CancelExecution, CheckIfResultsExist, GetResults, GetSummary, GetDuplicateDirs, GetWarnings, RevealInExplorer, FullReset } from '../wailsjs/go/processing/FrontendApp';
 import { FrontEnd_DuplicateGroup } from './models.js';
 
 document.querySelector('#app').innerHTML = htmlTemplate;
@@ -10,7 +10,10 @@ document.querySelector('#app').innerHTML = htmlTemplate;
 const progressBar = document.getElementById("progress-bar");
 const statusJob = document.getElementById("status-job");
 const statusFiles = document.getElementById("status-files");
+const statusBytes = document.getElementById("status-bytes");
 const statusDuplicates = document.getElementById("status-duplicates");
+const statusReclaimable = document.getElementById("status-reclaimable");
+const statusDirs = document.getElementById("status-dirs");
 const statusError = document.getElementById("status-error");
 const showResultsButton = document.getElementById('showResultsButton');
 const clearResultsButton = document.getElementById('clearResultsButton');
@@ -132,6 +135,15 @@ window.toggleAdvanced = function () {
     }
 };
 
+// splitLines returns the non-blank, trimmed lines of a textarea value.
+const splitLines = (value) => value
+    .split('\n')
+    .map(line => line.trim())
+    .filter(line => line !== '');
+
+// mibToBytes converts a MiB input value to whole bytes; empty means 0 (no bound).
+const mibToBytes = (value) => Math.round((parseFloat(value) || 0) * 1024 * 1024);
+
 // --- Execution Start Handler ---
 window.startProcess = function () {
     // 1. Gather data
@@ -149,13 +161,36 @@ window.startProcess = function () {
         cpus: parseInt(document.getElementById('cpus').value) || 0,
         bufSize: parseInt(document.getElementById('bufSize').value) || 0,
         debugMode: document.getElementById('debugMode').checked,
+        hashAlgorithm: document.getElementById('hashAlgorithm').value,
+        keepRules: splitLines(document.getElementById('keepRules').value),
+        resultFormats: Array.from(document.querySelectorAll('.result-format:checked'))
+            .map(input => input.value),
+        csvDelimiter: document.getElementById('csvDelimiter').value,
+        csvOmitBom: document.getElementById('csvOmitBom').checked,
+        include: splitLines(document.getElementById('includePatterns').value),
+        exclude: splitLines(document.getElementById('excludePatterns').value),
+        extensions: document.getElementById('extensions').value.split(','),
+        excludeExtensions: document.getElementById('excludeExtensions').value.split(','),
+        minSize: mibToBytes(document.getElementById('minSizeMiB').value),
+        maxSize: mibToBytes(document.getElementById('maxSizeMiB').value),
+        modifiedAfter: document.getElementById('modifiedAfter').value,
+        modifiedBefore: document.getElementById('modifiedBefore').value,
+        respectIgnoreFiles: document.getElementById('respectIgnoreFiles').checked,
+        scanVcsDirs: document.getElementById('scanVcsDirs').checked,
+        reportHardLinks: document.getElementById('reportHardLinks').checked,
+        symlinkPolicy: document.getElementById('symlinkPolicy').value,
+        compareDirs: document.getElementById('compareDirs').checked,
+        referenceDirs: splitLines(document.getElementById('referenceDirs').value),
     };
 
     // Clear old status/reset bar
     statusJob.textContent = "Starting up...";
     statusJob.classList.remove('status-value--success');
     statusFiles.textContent = "\u2014";
+    statusBytes.textContent = "\u2014";
     statusDuplicates.textContent = "\u2014";
+    statusReclaimable.textContent = "\u2014";
+    statusDirs.textContent = "\u2014";
     statusDuplicates.classList.remove('status-value--orange');
     statusError.textContent = "";
     statusError.style.display = "none";
@@ -250,6 +285,23 @@ function _applyUIReset() {
     document.getElementById('resultsDir').value = '';
     document.getElementById('cpus').value = '0';
     document.getElementById('bufSize').value = '1024';
+    document.getElementById('hashAlgorithm').value = 'md5';
+    document.getElementById('keepRules').value = '';
+    document.getElementById('referenceDirs').value = '';
+    document.getElementById('csvDelimiter').value = ',';
+    document.getElementById('csvOmitBom').checked = false;
+    ['includePatterns', 'excludePatterns', 'extensions', 'excludeExtensions',
+        'minSizeMiB', 'maxSizeMiB', 'modifiedAfter', 'modifiedBefore'].forEach(id => {
+        document.getElementById(id).value = '';
+    });
+    document.querySelectorAll('.result-format').forEach(input => {
+        input.checked = input.value === 'csv';
+    });
+    document.getElementById('respectIgnoreFiles').checked = false;
+    document.getElementById('scanVcsDirs').checked = false;
+    document.getElementById('reportHardLinks').checked = false;
+    document.getElementById('symlinkPolicy').value = 'skip';
+    document.getElementById('compareDirs').checked = false;
     document.getElementById('paranoidMode').checked = false;
     document.getElementById('debugMode').checked = false;
     document.getElementById('keepMemory').checked = true;
@@ -293,7 +345,10 @@ window.clearResults = function () {
     statusJob.textContent = 'Ready to run.';
     statusJob.classList.remove('status-value--success');
     statusFiles.textContent = '\u2014';
+    statusBytes.textContent = '\u2014';
     statusDuplicates.textContent = '\u2014';
+    statusReclaimable.textContent = '\u2014';
+    statusDirs.textContent = '\u2014';
     statusDuplicates.classList.remove('status-value--orange');
     statusError.textContent = '';
     statusError.style.display = 'none';
@@ -348,6 +403,12 @@ function setupStatusListeners() {
         }
     });
 
+    // 2b. Bytes read update (shows progress inside very large files)
+    runtime.EventsOn("bytesCount", (data) => {
+        const toMiB = (bytes) => (bytes / (1024 * 1024)).toFixed(1);
+        statusBytes.textContent = `${toMiB(data.current)} of ${toMiB(data.total)} MiB`;
+    });
+
     // 3. Error Event
     runtime.EventsOn("errorUpdate", (message) => {
         statusJob.textContent = "Error: Process Failed";
@@ -375,6 +436,32 @@ function setupStatusListeners() {
     GetResults()
         .then(groups => renderResults(groups))
         .catch(err => console.error('GetResults error:', err));
+
+    // Fetch and display the scan summary
+    GetSummary()
+        .then(summary => {
+            if (!summary) return;
+            const toMiB = (bytes) => (bytes / (1024 * 1024)).toFixed(1);
+            statusReclaimable.textContent = `${toMiB(summary.reclaimableBytes)} of ${toMiB(summary.bytesScanned)} MiB in ${summary.duplicateFiles} files`;
+        })
+        .catch(err => console.error('GetSummary error:', err));
+
+    // Fetch the identical directories, only found when whole directories are compared
+    GetDuplicateDirs()
+        .then(sets => {
+            if (!document.getElementById('compareDirs').checked) return;
+            statusDirs.textContent = `${(sets || []).length} sets`;
+        })
+        .catch(err => console.error('GetDuplicateDirs error:', err));
+
+    // Problems that did not stop the scan, e.g. a hash cache that could not be written
+    GetWarnings()
+        .then(warnings => {
+            if (!warnings || warnings.length === 0) return;
+            statusError.textContent = warnings.map(w => `Warning: ${w}`).join('\n');
+            statusError.style.display = '';
+        })
+        .catch(err => console.error('GetWarnings error:', err));
 });
 
     // fullReset event: backend notifies the frontend after FullReset() completes
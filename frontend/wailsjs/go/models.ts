@@ -1,3 +1,233 @@
+export namespace actions {
+	
+	export class Outcome {
+	    path: string;
+	    original: string;
+	    action: string;
+	    destination: string;
+	    bytes: number;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Outcome(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.original = source["original"];
+	        this.action = source["action"];
+	        this.destination = source["destination"];
+	        this.bytes = source["bytes"];
+	        this.error = source["error"];
+	    }
+	}
+	export class Report {
+	    session: string;
+	    action: string;
+	    dryRun: boolean;
+	    outcomes: Outcome[];
+	    filesActed: number;
+	    filesFailed: number;
+	    bytesFreed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session = source["session"];
+	        this.action = source["action"];
+	        this.dryRun = source["dryRun"];
+	        this.outcomes = this.convertValues(source["outcomes"], Outcome);
+	        this.filesActed = source["filesActed"];
+	        this.filesFailed = source["filesFailed"];
+	        this.bytesFreed = source["bytesFreed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Request {
+	    action: string;
+	    quarantineDir: string;
+	    dryRun: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Request(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.quarantineDir = source["quarantineDir"];
+	        this.dryRun = source["dryRun"];
+	    }
+	}
+	export class RestoreRequest {
+	    session: string;
+	    group: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session = source["session"];
+	        this.group = source["group"];
+	    }
+	}
+	export class Session {
+	    id: string;
+	    action: string;
+	    started: string;
+	    files: number;
+	    restored: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Session(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.action = source["action"];
+	        this.started = source["started"];
+	        this.files = source["files"];
+	        this.restored = source["restored"];
+	    }
+	}
+
+}
+
+export namespace db {
+	
+	export class CacheEntry {
+	    path: string;
+	    seenAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CacheEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.seenAt = source["seenAt"];
+	    }
+	}
+	export class CacheStats {
+	    path: string;
+	    entries: number;
+	    sizeBytes: number;
+	    schemaVersion: number;
+	    oldest: CacheEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CacheStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.entries = source["entries"];
+	        this.sizeBytes = source["sizeBytes"];
+	        this.schemaVersion = source["schemaVersion"];
+	        this.oldest = this.convertValues(source["oldest"], CacheEntry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportReport {
+	    imported: number;
+	    skipped: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.imported = source["imported"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+	export class PruneReport {
+	    missing: number;
+	    old: number;
+	    prefixed: number;
+	    sizeBefore: number;
+	    sizeAfter: number;
+	    entriesLeft: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PruneReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.missing = source["missing"];
+	        this.old = source["old"];
+	        this.prefixed = source["prefixed"];
+	        this.sizeBefore = source["sizeBefore"];
+	        this.sizeAfter = source["sizeAfter"];
+	        this.entriesLeft = source["entriesLeft"];
+	    }
+	}
+	export class PruneRequest {
+	    missing: boolean;
+	    olderThanDays: number;
+	    prefixes: string[];
+	    vacuum: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PruneRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.missing = source["missing"];
+	        this.olderThanDays = source["olderThanDays"];
+	        this.prefixes = source["prefixes"];
+	        this.vacuum = source["vacuum"];
+	    }
+	}
+
+}
+
 export namespace models {
 	
 	export class ExecutionParams {
@@ -9,6 +239,27 @@ export namespace models {
 	    cpus: number;
 	    bufSize: number;
 	    debugMode: boolean;
+	    hashAlgorithm: string;
+	    partialHashKiB: number;
+	    keepRules: string[];
+	    resultFormats: string[];
+	    csvDelimiter: string;
+	    csvOmitBom: boolean;
+	    csvLayout: number;
+	    include: string[];
+	    exclude: string[];
+	    extensions: string[];
+	    excludeExtensions: string[];
+	    minSize: number;
+	    maxSize: number;
+	    modifiedAfter: string;
+	    modifiedBefore: string;
+	    respectIgnoreFiles: boolean;
+	    scanVcsDirs: boolean;
+	    reportHardLinks: boolean;
+	    symlinkPolicy: string;
+	    compareDirs: boolean;
+	    referenceDirs: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ExecutionParams(source);
@@ -24,15 +275,42 @@ export namespace models {
 	        this.cpus = source["cpus"];
 	        this.bufSize = source["bufSize"];
 	        this.debugMode = source["debugMode"];
+	        this.hashAlgorithm = source["hashAlgorithm"];
+	        this.partialHashKiB = source["partialHashKiB"];
+	        this.keepRules = source["keepRules"];
+	        this.resultFormats = source["resultFormats"];
+	        this.csvDelimiter = source["csvDelimiter"];
+	        this.csvOmitBom = source["csvOmitBom"];
+	        this.csvLayout = source["csvLayout"];
+	        this.include = source["include"];
+	        this.exclude = source["exclude"];
+	        this.extensions = source["extensions"];
+	        this.excludeExtensions = source["excludeExtensions"];
+	        this.minSize = source["minSize"];
+	        this.maxSize = source["maxSize"];
+	        this.modifiedAfter = source["modifiedAfter"];
+	        this.modifiedBefore = source["modifiedBefore"];
+	        this.respectIgnoreFiles = source["respectIgnoreFiles"];
+	        this.scanVcsDirs = source["scanVcsDirs"];
+	        this.reportHardLinks = source["reportHardLinks"];
+	        this.symlinkPolicy = source["symlinkPolicy"];
+	        this.compareDirs = source["compareDirs"];
+	        this.referenceDirs = source["referenceDirs"];
 	    }
 	}
 	export class FileHash {
 	    FileName: string;
 	    FilePath: string;
 	    Hash: string;
+	    HashAlgorithm: string;
+	    PartialHash: string;
+	    PartialWindow: number;
 	    ModTime: string;
 	    FileSize: number;
 	    DuplicatesFound: FileHash[];
+	    HardLinks: string[];
+	    ExternalLinks: number;
+	    Imported: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new FileHash(source);
@@ -43,9 +321,15 @@ export namespace models {
 	        this.FileName = source["FileName"];
 	        this.FilePath = source["FilePath"];
 	        this.Hash = source["Hash"];
+	        this.HashAlgorithm = source["HashAlgorithm"];
+	        this.PartialHash = source["PartialHash"];
+	        this.PartialWindow = source["PartialWindow"];
 	        this.ModTime = source["ModTime"];
 	        this.FileSize = source["FileSize"];
 	        this.DuplicatesFound = this.convertValues(source["DuplicatesFound"], FileHash);
+	        this.HardLinks = source["HardLinks"];
+	        this.ExternalLinks = source["ExternalLinks"];
+	        this.Imported = source["Imported"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -69,3 +353,140 @@ export namespace models {
 
 }
 
+
+export namespace results {
+	
+	export class Breakdown {
+	    key: string;
+	    duplicateFiles: number;
+	    reclaimableBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Breakdown(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.duplicateFiles = source["duplicateFiles"];
+	        this.reclaimableBytes = source["reclaimableBytes"];
+	    }
+	}
+	export class DirectorySet {
+	    paths: string[];
+	    files: number;
+	    size: number;
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectorySet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.paths = source["paths"];
+	        this.files = source["files"];
+	        this.size = source["size"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class DirectorySubset {
+	    path: string;
+	    superset: string;
+	    files: number;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectorySubset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.superset = source["superset"];
+	        this.files = source["files"];
+	        this.size = source["size"];
+	    }
+	}
+	export class GroupSummary {
+	    id: string;
+	    original: string;
+	    files: number;
+	    size: number;
+	    reclaimableBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GroupSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.original = source["original"];
+	        this.files = source["files"];
+	        this.size = source["size"];
+	        this.reclaimableBytes = source["reclaimableBytes"];
+	    }
+	}
+	export class ScanSummary {
+	    filesScanned: number;
+	    bytesScanned: number;
+	    duplicateGroups: number;
+	    duplicateFiles: number;
+	    reclaimableBytes: number;
+	    largestGroups: GroupSummary[];
+	    byExtension: Breakdown[];
+	    byDirectory: Breakdown[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filesScanned = source["filesScanned"];
+	        this.bytesScanned = source["bytesScanned"];
+	        this.duplicateGroups = source["duplicateGroups"];
+	        this.duplicateFiles = source["duplicateFiles"];
+	        this.reclaimableBytes = source["reclaimableBytes"];
+	        this.largestGroups = this.convertValues(source["largestGroups"], GroupSummary);
+	        this.byExtension = this.convertValues(source["byExtension"], Breakdown);
+	        this.byDirectory = this.convertValues(source["byDirectory"], Breakdown);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Symlink {
+	    path: string;
+	    target: string;
+	    broken?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Symlink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.target = source["target"];
+	        this.broken = source["broken"];
+	    }
+	}
+
+}
@@ -1,15 +1,46 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {actions} from '../models';
+import {db} from '../models';
 import {models} from '../models';
+import {results} from '../models';
+
+export function ApplyAction(arg1:actions.Request):Promise<actions.Report>;
+
+export function CacheStats(arg1:number):Promise<db.CacheStats>;
 
 export function CancelExecution():Promise<void>;
 
 export function CheckIfResultsExist():Promise<boolean>;
 
+export function ExportCache(arg1:string,arg2:string):Promise<number>;
+
+export function ExportResults(arg1:Array<string>):Promise<Array<string>>;
+
 export function FullReset():Promise<void>;
 
+export function GetDuplicateDirs():Promise<Array<results.DirectorySet>>;
+
+export function GetHardLinks():Promise<Array<models.FileHash>>;
+
 export function GetResults():Promise<Array<models.FileHash>>;
 
+export function GetSubsetDirs():Promise<Array<results.DirectorySubset>>;
+
+export function GetSummary():Promise<results.ScanSummary>;
+
+export function GetSymlinks():Promise<Array<results.Symlink>>;
+
+export function GetWarnings():Promise<Array<string>>;
+
+export function ImportCache(arg1:string,arg2:string):Promise<db.ImportReport>;
+
+export function JournalSessions():Promise<Array<actions.Session>>;
+
+export function PruneCache(arg1:db.PruneRequest):Promise<db.PruneReport>;
+
+export function RestoreFromJournal(arg1:actions.RestoreRequest):Promise<actions.Report>;
+
 export function RevealInExplorer(arg1:string):Promise<void>;
 
 export function SelectFolder():Promise<string>;
@@ -17,3 +48,5 @@ export function SelectFolder():Promise<string>;
 export function ShowResults():Promise<void>;
 
 export function StartExecution(arg1:models.ExecutionParams):Promise<void>;
+
+export function VacuumCache():Promise<db.CacheStats>;
@@ -2,6 +2,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ApplyAction(arg1) {
+  return window['go']['processing']['FrontendApp']['ApplyAction'](arg1);
+}
+
+export function CacheStats(arg1) {
+  return window['go']['processing']['FrontendApp']['CacheStats'](arg1);
+}
+
 export function CancelExecution() {
   return window['go']['processing']['FrontendApp']['CancelExecution']();
 }
@@ -10,14 +18,62 @@ export function CheckIfResultsExist() {
   return window['go']['processing']['FrontendApp']['CheckIfResultsExist']();
 }
 
+export function ExportCache(arg1, arg2) {
+  return window['go']['processing']['FrontendApp']['ExportCache'](arg1, arg2);
+}
+
+export function ExportResults(arg1) {
+  return window['go']['processing']['FrontendApp']['ExportResults'](arg1);
+}
+
 export function FullReset() {
   return window['go']['processing']['FrontendApp']['FullReset']();
 }
 
+export function GetDuplicateDirs() {
+  return window['go']['processing']['FrontendApp']['GetDuplicateDirs']();
+}
+
+export function GetHardLinks() {
+  return window['go']['processing']['FrontendApp']['GetHardLinks']();
+}
+
 export function GetResults() {
   return window['go']['processing']['FrontendApp']['GetResults']();
 }
 
+export function GetSubsetDirs() {
+  return window['go']['processing']['FrontendApp']['GetSubsetDirs']();
+}
+
+export function GetSummary() {
+  return window['go']['processing']['FrontendApp']['GetSummary']();
+}
+
+export function GetSymlinks() {
+  return window['go']['processing']['FrontendApp']['GetSymlinks']();
+}
+
+export function GetWarnings() {
+  return window['go']['processing']['FrontendApp']['GetWarnings']();
+}
+
+export function ImportCache(arg1, arg2) {
+  return window['go']['processing']['FrontendApp']['ImportCache'](arg1, arg2);
+}
+
+export function JournalSessions() {
+  return window['go']['processing']['FrontendApp']['JournalSessions']();
+}
+
+export function PruneCache(arg1) {
+  return window['go']['processing']['FrontendApp']['PruneCache'](arg1);
+}
+
+export function RestoreFromJournal(arg1) {
+  return window['go']['processing']['FrontendApp']['RestoreFromJournal'](arg1);
+}
+
 export function RevealInExplorer(arg1) {
   return window['go']['processing']['FrontendApp']['RevealInExplorer'](arg1);
 }
@@ -33,3 +89,7 @@ export function ShowResults() {
 export function StartExecution(arg1) {
   return window['go']['processing']['FrontendApp']['StartExecution'](arg1);
 }
+
+export function VacuumCache() {
+  return window['go']['processing']['FrontendApp']['VacuumCache']();
+}
@@ -0,0 +1,308 @@
+package actions
+
+import (
+	log "DuDe/internal/common/logger"
+	"DuDe/internal/filters"
+	"DuDe/internal/models"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Supported actions.
+const (
+	Delete     = "delete"     // remove the duplicate
+	Quarantine = "quarantine" // move the duplicate into QuarantineDir, at its absolute path below it
+	Trash      = "trash"      // move the duplicate to the freedesktop.org Trash (Linux only)
+	Link       = "link"       // replace the duplicate with a hard link to the original, or a symlink across filesystems
+	Symlink    = "symlink"    // replace the duplicate with a symlink to the original
+)
+
+// Request describes what to do with every duplicate of the last results.
+// The original of each group is never touched.
+type Request struct {
+	Action        string   `json:"action"`
+	QuarantineDir string   `json:"quarantineDir"`
+	DryRun        bool     `json:"dryRun"` // verify and report only, touch nothing
+	ReferenceDirs []string `json:"-"`      // read-only directories whose files are never acted on, filled in by the caller
+	JournalDir    string   `json:"-"`      // directory of the undo journal, filled in by the caller; "" disables it
+}
+
+// Outcome records what happened to a single duplicate.
+type Outcome struct {
+	Path        string `json:"path"`
+	Original    string `json:"original"`
+	Action      string `json:"action"`
+	Destination string `json:"destination"`
+	Bytes       int64  `json:"bytes"` // bytes reclaimed by acting on this file
+	Error       string `json:"error"`
+}
+
+// Succeeded reports whether the action was carried out (or would be, in a dry run).
+func (o Outcome) Succeeded() bool {
+	return o.Error == ""
+}
+
+// Report summarises an applied Request.
+type Report struct {
+	Session     string    `json:"session"` // journal session, "" if nothing was journaled
+	Action      string    `json:"action"`
+	DryRun      bool      `json:"dryRun"`
+	Outcomes    []Outcome `json:"outcomes"`
+	FilesActed  int       `json:"filesActed"`
+	FilesFailed int       `json:"filesFailed"`
+	BytesFreed  int64     `json:"bytesFreed"` // bytes reclaimed in total
+}
+
+// performer carries out an action on dup, whose group original is original.
+// It returns where the file ended up, "" if it no longer exists anywhere,
+// and the number of bytes reclaimed.
+type performer func(ctx context.Context, dup, original models.FileHash, req Request) (string, int64, error)
+
+var performers = map[string]performer{
+	Delete:     deleteFile,
+	Quarantine: quarantineFile,
+	Trash:      trashFile,
+	Link:       linkFile,
+	Symlink:    symlinkFile,
+}
+
+// Names returns the names of all supported actions.
+func Names() []string {
+	return []string{Delete, Quarantine, Trash, Link, Symlink}
+}
+
+// Validate normalises the request and checks it can be carried out.
+func (r *Request) Validate() error {
+	r.Action = strings.ToLower(strings.TrimSpace(r.Action))
+	if _, ok := performers[r.Action]; !ok {
+		return fmt.Errorf("%w: %q (supported: %s)", ErrUnknownAction, r.Action, strings.Join(Names(), ", "))
+	}
+	if r.Action == Quarantine && r.QuarantineDir == "" {
+		return ErrNoQuarantineDir
+	}
+	if r.Action == Trash && !trashSupported {
+		return ErrTrashUnsupported
+	}
+	return nil
+}
+
+// Apply carries out req on every duplicate of every group. Each file, and the original
+// it duplicates, is re-verified against its scanned size, modification time and hash
+// immediately before it is touched, so stale results never destroy data.
+// Every file acted on is recorded in the undo journal in req.JournalDir, see RestoreFromJournal:
+// once before it is touched and once the action succeeded, so even a crash in between
+// leaves a record to restore from.
+// A duplicate with hard links is acted on under each of its walked names, since its
+// data is only reclaimed once all of them are gone.
+// Failures are recorded per file; only an invalid request, a journal that cannot be
+// written or cancellation returns an error.
+func Apply(ctx context.Context, groups []models.FileHash, req Request) (*Report, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	perform := performers[req.Action]
+
+	report := &Report{Action: req.Action, DryRun: req.DryRun}
+
+	var j *journal
+	if req.JournalDir != "" && !req.DryRun {
+		var err error
+		if j, err = openJournal(req.JournalDir); err != nil {
+			return nil, err
+		}
+		defer j.Close()
+		report.Session = newSessionID()
+	}
+
+	for _, group := range groups {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+
+		// The kept copy must still be intact before any of its duplicates goes away.
+		originalErr := Verify(ctx, group)
+
+		for _, dup := range group.DuplicatesFound {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+
+			err := originalErr
+			switch {
+			case slices.ContainsFunc(names(dup), func(name string) bool { return isReference(name, req.ReferenceDirs) }):
+				err = ErrReferenceFile
+			case err != nil:
+				err = fmt.Errorf("original %s: %w", group.FilePath, err)
+			default:
+				err = verifyNames(ctx, dup)
+			}
+
+			// The data of a duplicate is only reclaimed once every hard link to it is gone:
+			// each walked name is acted on, and the bytes count for the last one.
+			freed := err == nil && dup.ExternalLinks == 0
+			for i, name := range names(dup) {
+				if ctx.Err() != nil {
+					return report, ctx.Err()
+				}
+				file := dup
+				file.FilePath, file.FileName, file.HardLinks = name, filepath.Base(name), nil
+
+				// A dry run reports what would be reclaimed if every file could be acted on.
+				outcome := Outcome{Path: name, Original: group.FilePath, Action: req.Action, Bytes: dup.FileSize}
+				var stop error
+				nameErr := err
+				if nameErr == nil && !req.DryRun {
+					entry := JournalEntry{
+						Session:       report.Session,
+						Action:        req.Action,
+						Path:          name,
+						Original:      group.FilePath,
+						Destination:   plannedDestination(file, group, req),
+						Hash:          dup.Hash,
+						HashAlgorithm: dup.HashAlgorithm,
+						Size:          dup.FileSize,
+						ModTime:       dup.ModTime,
+					}
+					// Acting on a file without a way to undo it is not safe.
+					if j != nil {
+						entry.Pending = true
+						if err := j.append(entry); err != nil {
+							log.ErrorWithFuncName(fmt.Sprintf("Stopping %s: %v", req.Action, err))
+							return report, err
+						}
+					}
+
+					outcome.Destination, outcome.Bytes, nameErr = perform(ctx, file, group, req)
+
+					if nameErr == nil && j != nil {
+						entry.Pending, entry.Time, entry.Destination = false, "", outcome.Destination
+						stop = j.append(entry)
+					}
+				}
+
+				if nameErr != nil {
+					freed = false
+					outcome.Error = nameErr.Error()
+					outcome.Bytes = 0
+					report.FilesFailed++
+					log.WarnWithFuncName(fmt.Sprintf("%s skipped for %s: %v", req.Action, name, nameErr))
+				} else {
+					if !freed || i < len(dup.HardLinks) {
+						outcome.Bytes = 0
+					}
+					report.FilesActed++
+					report.BytesFreed += outcome.Bytes
+				}
+				report.Outcomes = append(report.Outcomes, outcome)
+
+				if stop != nil {
+					log.ErrorWithFuncName(fmt.Sprintf("Stopping %s: %v", req.Action, stop))
+					return report, stop
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// Prune returns groups without the duplicates report acted on, dropping groups
+// that are left without any duplicate. Dry runs leave the groups unchanged.
+func Prune(groups []models.FileHash, report *Report) []models.FileHash {
+	if report == nil || report.DryRun {
+		return groups
+	}
+
+	acted := make(map[string]bool)
+	for _, o := range report.Outcomes {
+		if o.Succeeded() {
+			acted[o.Path] = true
+		}
+	}
+
+	var result []models.FileHash
+	for _, group := range groups {
+		var remaining []models.FileHash
+		for _, dup := range group.DuplicatesFound {
+			// A duplicate stays as long as any of its names is left.
+			var left []string
+			for _, name := range names(dup) {
+				if !acted[name] {
+					left = append(left, name)
+				}
+			}
+			if len(left) == 0 {
+				continue
+			}
+			if len(left) < len(names(dup)) {
+				dup.FilePath, dup.FileName, dup.HardLinks = left[0], filepath.Base(left[0]), left[1:]
+			}
+			remaining = append(remaining, dup)
+		}
+		if len(remaining) > 0 {
+			group.DuplicatesFound = remaining
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// plannedDestination returns where req will put dup, as far as it is known before acting:
+// the trash picks a free name only while moving the file.
+func plannedDestination(dup, original models.FileHash, req Request) string {
+	switch req.Action {
+	case Quarantine:
+		return quarantineDestination(dup, req)
+	case Link:
+		return original.FilePath
+	case Symlink:
+		if target, err := filepath.Abs(original.FilePath); err == nil {
+			return target
+		}
+		return original.FilePath
+	}
+	return ""
+}
+
+// names returns every walked path of fh: its own and those of its hard links.
+func names(fh models.FileHash) []string {
+	return append([]string{fh.FilePath}, fh.HardLinks...)
+}
+
+// verifyNames verifies fh like Verify, and that each of its hard links still is the same file.
+func verifyNames(ctx context.Context, fh models.FileHash) error {
+	if err := Verify(ctx, fh); err != nil {
+		return err
+	}
+	for _, link := range fh.HardLinks {
+		same, err := sameFile(link, fh.FilePath)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrFileChanged, err)
+		}
+		if !same {
+			return fmt.Errorf("%w: %s is no longer a link to it", ErrFileChanged, link)
+		}
+	}
+	return nil
+}
+
+// isReference reports whether path lies inside one of the reference directories.
+func isReference(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if filters.Within(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func deleteFile(ctx context.Context, dup, original models.FileHash, req Request) (string, int64, error) {
+	if err := os.Remove(dup.FilePath); err != nil {
+		return "", 0, fmt.Errorf("failed to delete: %w", err)
+	}
+	return "", dup.FileSize, nil
+}
@@ -0,0 +1,16 @@
+package actions
+
+import "errors"
+
+var (
+	ErrUnknownAction     = errors.New("unknown action")
+	ErrNoQuarantineDir   = errors.New("a quarantine directory is required")
+	ErrFileChanged       = errors.New("file changed since it was scanned")
+	ErrDestinationExists = errors.New("destination already exists")
+	ErrTrashUnsupported  = errors.New("trash is not supported on this platform")
+	ErrNoResults         = errors.New("no results to act on")
+	ErrSessionNotFound   = errors.New("no such session in the journal")
+	ErrGroupNotFound     = errors.New("no such group in the session")
+	ErrReferenceFile     = errors.New("file lies inside a reference directory")
+	ErrNotInTrash        = errors.New("file not found in the trash")
+)
@@ -0,0 +1,140 @@
+package actions
+
+import (
+	"DuDe/internal/common"
+	log "DuDe/internal/common/logger"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Restore is the journal action recording that an earlier entry was reversed.
+const Restore = "restore"
+
+// JournalEntry is one line of the append-only undo journal. Every file an action
+// touches gets a pending entry, written right before the action, and a second entry
+// without Pending once the action succeeded.
+type JournalEntry struct {
+	Session       string `json:"session"`
+	Time          string `json:"time"`
+	Action        string `json:"action"`
+	Path          string `json:"path"`        // the duplicate that was acted on
+	Original      string `json:"original"`    // the kept original of its group
+	Destination   string `json:"destination"` // where the duplicate ended up, "" if deleted
+	Hash          string `json:"hash"`
+	HashAlgorithm string `json:"hashAlgorithm"`
+	Size          int64  `json:"size"`
+	ModTime       string `json:"modTime"`
+	Pending       bool   `json:"pending,omitempty"` // the action was about to start; a crash may have interrupted it
+}
+
+// Session summarises the entries of one Apply call.
+type Session struct {
+	ID       string `json:"id"`
+	Action   string `json:"action"`
+	Started  string `json:"started"`
+	Files    int    `json:"files"`
+	Restored int    `json:"restored"`
+}
+
+type journal struct {
+	f *os.File
+}
+
+// openJournal opens the journal in dir for appending, creating it if needed.
+func openJournal(dir string) (*journal, error) {
+	f, err := os.OpenFile(filepath.Join(dir, common.JournalFilename), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	return &journal{f: f}, nil
+}
+
+// append writes e as a single line and syncs it to disk before returning.
+func (j *journal) append(e JournalEntry) error {
+	if e.Time == "" {
+		e.Time = time.Now().Format(time.RFC3339)
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return j.f.Sync()
+}
+
+func (j *journal) Close() error {
+	return j.f.Close()
+}
+
+// newSessionID returns a sortable, practically unique session identifier.
+func newSessionID() string {
+	return time.Now().UTC().Format("20060102T150405.000000000Z")
+}
+
+// ReadJournal returns all entries of the journal in dir, oldest first.
+// A missing journal has no entries. Lines that cannot be parsed, such as one
+// torn by a crash, are skipped.
+func ReadJournal(dir string) ([]JournalEntry, error) {
+	f, err := os.Open(filepath.Join(dir, common.JournalFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			log.WarnWithFuncName(fmt.Sprintf("Skipping journal line %d: %v", line, err))
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Sessions lists the sessions recorded in the journal in dir, oldest first.
+func Sessions(dir string) ([]Session, error) {
+	entries, err := ReadJournal(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []Session
+	index := make(map[string]int)
+	// A file counts once, whether its action completed or was interrupted.
+	files := make(map[[2]string]bool)
+	for _, e := range entries {
+		i, ok := index[e.Session]
+		if !ok {
+			i = len(sessions)
+			index[e.Session] = i
+			sessions = append(sessions, Session{ID: e.Session, Started: e.Time})
+		}
+		if e.Action == Restore {
+			sessions[i].Restored++
+			continue
+		}
+		sessions[i].Action = e.Action
+		if key := [2]string{e.Session, e.Path}; !files[key] {
+			files[key] = true
+			sessions[i].Files++
+		}
+	}
+	return sessions, nil
+}
@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"DuDe/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// linkFile replaces dup with a hard link to original, falling back to
+// a symlink when the two live on different filesystems.
+func linkFile(ctx context.Context, dup, original models.FileHash, req Request) (string, int64, error) {
+	if same, err := sameFile(dup.FilePath, original.FilePath); err != nil {
+		return "", 0, err
+	} else if same {
+		// Already a hard link to the original; nothing left to reclaim.
+		return original.FilePath, 0, nil
+	}
+
+	err := replaceAtomically(dup.FilePath, func(tmp string) error {
+		return os.Link(original.FilePath, tmp)
+	})
+	if errors.Is(err, syscall.EXDEV) {
+		return symlinkFile(ctx, dup, original, req)
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hard link: %w", err)
+	}
+	return original.FilePath, dup.FileSize, nil
+}
+
+// symlinkFile replaces dup with a symlink to the absolute path of original.
+func symlinkFile(ctx context.Context, dup, original models.FileHash, req Request) (string, int64, error) {
+	target, err := filepath.Abs(original.FilePath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	err = replaceAtomically(dup.FilePath, func(tmp string) error {
+		return os.Symlink(target, tmp)
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to symlink: %w", err)
+	}
+	return target, dup.FileSize, nil
+}
+
+// replaceAtomically lets create make the replacement under a temporary name next to
+// path and renames it over path, so path exists at every point in time.
+func replaceAtomically(path string, create func(tmp string) error) error {
+	tmp, err := tempName(path)
+	if err != nil {
+		return err
+	}
+	if err := create(tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// tempName returns an unused name in the directory of path.
+func tempName(path string) (string, error) {
+	// CreateTemp reserves a unique name; the caller recreates it as a link.
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".dude-*")
+	if err != nil {
+		return "", err
+	}
+	name := f.Name()
+	f.Close()
+	if err := os.Remove(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// sameFile reports whether both paths refer to the same file on disk.
+func sameFile(path1, path2 string) (bool, error) {
+	info1, err := os.Stat(path1)
+	if err != nil {
+		return false, err
+	}
+	info2, err := os.Stat(path2)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(info1, info2), nil
+}
@@ -0,0 +1,104 @@
+package actions
+
+import (
+	"DuDe/internal/common/ctxio"
+	"DuDe/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// quarantineFile moves dup into the quarantine directory, at its absolute path below it.
+func quarantineFile(ctx context.Context, dup, original models.FileHash, req Request) (string, int64, error) {
+	dest := quarantineDestination(dup, req)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", 0, fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	if err := moveFile(ctx, dup.FilePath, dest); err != nil {
+		return "", 0, err
+	}
+	return dest, dup.FileSize, nil
+}
+
+// quarantineDestination returns where quarantineFile moves dup.
+func quarantineDestination(dup models.FileHash, req Request) string {
+	return filepath.Join(req.QuarantineDir, quarantinePath(dup.FilePath))
+}
+
+// quarantinePath returns the path of file below the quarantine directory: its volume-less
+// absolute path. Unlike a path relative to its scan root, it cannot collide with a file
+// of another root of the same name, and tells where the file came from.
+func quarantinePath(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		abs = file
+	}
+	return strings.TrimPrefix(abs, filepath.VolumeName(abs))
+}
+
+// moveFile renames src to dest without ever overwriting dest.
+// Across filesystems it copies, syncs and only then removes src.
+func moveFile(ctx context.Context, src, dest string) error {
+	if _, err := os.Lstat(dest); err == nil {
+		return fmt.Errorf("%w: %s", ErrDestinationExists, dest)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	err := os.Rename(src, dest)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return fmt.Errorf("failed to move: %w", err)
+	}
+
+	if err := copyFile(ctx, src, dest); err != nil {
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("copied to %s but failed to remove source: %w", dest, err)
+	}
+	return nil
+}
+
+// copyFile copies src to the new file dest, preserving its mode and modification time.
+// A partially written dest is removed on failure.
+func copyFile(ctx context.Context, src, dest string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(dest)
+		}
+	}()
+
+	if _, err = ctxio.Copy(ctx, out, in, nil); err != nil {
+		return fmt.Errorf("failed to copy to %s: %w", dest, err)
+	}
+	if err = out.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", dest, err)
+	}
+	if err = out.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", dest, err)
+	}
+	return os.Chtimes(dest, info.ModTime(), info.ModTime())
+}
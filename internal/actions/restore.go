@@ -0,0 +1,250 @@
+package actions
+
+import (
+	log "DuDe/internal/common/logger"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RestoreRequest selects what RestoreFromJournal reverses.
+type RestoreRequest struct {
+	Session string `json:"session"` // session to reverse, "" for the most recent one
+	Group   string `json:"group"`   // original path of a single group to reverse, "" for the whole session
+}
+
+// restorer reverses a journaled action, putting e.Path back in place.
+type restorer func(ctx context.Context, e JournalEntry) error
+
+var restorers = map[string]restorer{
+	Delete:     restoreDeleted,
+	Quarantine: restoreMoved,
+	Trash:      restoreTrashed,
+	Link:       restoreLinked,
+	Symlink:    restoreLinked,
+}
+
+// RestoreFromJournal reverses the actions of a session recorded in the journal in dir,
+// newest first. Entries that were already restored are skipped, so a partially failed
+// restore can simply be repeated. Every restored file is journaled as well.
+// A file whose action was interrupted, so that only its pending entry was written, is
+// restored if the action took effect and left alone otherwise.
+func RestoreFromJournal(ctx context.Context, dir string, req RestoreRequest) (*Report, error) {
+	entries, err := ReadJournal(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	session := req.Session
+	if session == "" {
+		for _, e := range entries {
+			if e.Action != Restore {
+				session = e.Session
+			}
+		}
+	}
+
+	restored := make(map[string]bool)
+	var todo []JournalEntry
+	latest := make(map[string]int) // index in todo of the latest entry of each path
+	sessionFound := false
+	for _, e := range entries {
+		if e.Session != session {
+			continue
+		}
+		sessionFound = true
+		if e.Action == Restore {
+			restored[e.Path] = true
+			continue
+		}
+		if req.Group != "" && filepath.Clean(e.Original) != filepath.Clean(req.Group) {
+			continue
+		}
+		if i, ok := latest[e.Path]; ok {
+			todo[i] = e
+		} else {
+			latest[e.Path] = len(todo)
+			todo = append(todo, e)
+		}
+	}
+	if !sessionFound {
+		return nil, fmt.Errorf("%w: %q", ErrSessionNotFound, session)
+	}
+	if len(todo) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrGroupNotFound, req.Group)
+	}
+
+	j, err := openJournal(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer j.Close()
+
+	report := &Report{Session: session, Action: Restore}
+
+	for i := len(todo) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+
+		e := todo[i]
+		if restored[e.Path] {
+			continue
+		}
+
+		var err error
+		if e.Pending {
+			var acted bool
+			if acted, err = settleInterrupted(&e); err == nil && !acted {
+				continue
+			}
+		}
+
+		restore, ok := restorers[e.Action]
+		switch {
+		case err != nil:
+		case !ok:
+			err = fmt.Errorf("%w: %q", ErrUnknownAction, e.Action)
+		default:
+			err = restore(ctx, e)
+		}
+		outcome := Outcome{Path: e.Path, Original: e.Original, Action: Restore, Destination: e.Destination}
+
+		if err != nil {
+			outcome.Error = err.Error()
+			report.FilesFailed++
+			log.WarnWithFuncName(fmt.Sprintf("restore skipped for %s: %v", e.Path, err))
+		} else {
+			report.FilesActed++
+		}
+		report.Outcomes = append(report.Outcomes, outcome)
+
+		if err == nil {
+			restored[e.Path] = true
+			e.Action, e.Time, e.Pending = Restore, "", false
+			if err := j.append(e); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// settleInterrupted works out whether the interrupted action of a pending entry took
+// effect, filling in the destination the trash picked for the file.
+func settleInterrupted(e *JournalEntry) (bool, error) {
+	info, err := os.Lstat(e.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	switch e.Action {
+	case Link, Symlink:
+		// The duplicate is replaced atomically, so it is always there.
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrFileChanged, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true, nil
+		}
+		return sameFile(e.Path, e.Original)
+	case Trash:
+		if err == nil {
+			return false, nil
+		}
+		e.Destination, err = findTrashed(e.Path)
+		return err == nil, err
+	default:
+		return err != nil, nil
+	}
+}
+
+// restoreDeleted recreates a deleted duplicate from the original it duplicated.
+func restoreDeleted(ctx context.Context, e JournalEntry) error {
+	if err := ensureAbsent(e.Path); err != nil {
+		return err
+	}
+	if err := verifyHash(ctx, e.Original, e.HashAlgorithm, e.Hash); err != nil {
+		return fmt.Errorf("original %s: %w", e.Original, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0o755); err != nil {
+		return err
+	}
+	if err := copyFile(ctx, e.Original, e.Path); err != nil {
+		return err
+	}
+	return restoreModTime(e)
+}
+
+// restoreMoved moves a quarantined duplicate back to where it was found.
+func restoreMoved(ctx context.Context, e JournalEntry) error {
+	if err := ensureAbsent(e.Path); err != nil {
+		return err
+	}
+	if err := verifyHash(ctx, e.Destination, e.HashAlgorithm, e.Hash); err != nil {
+		return fmt.Errorf("%s: %w", e.Destination, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0o755); err != nil {
+		return err
+	}
+	return moveFile(ctx, e.Destination, e.Path)
+}
+
+// restoreTrashed moves a trashed duplicate back and drops its .trashinfo file.
+func restoreTrashed(ctx context.Context, e JournalEntry) error {
+	if err := restoreMoved(ctx, e); err != nil {
+		return err
+	}
+	trashDir := filepath.Dir(filepath.Dir(e.Destination))
+	info := filepath.Join(trashDir, "info", filepath.Base(e.Destination)+".trashinfo")
+	if err := os.Remove(info); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.WarnWithFuncName(fmt.Sprintf("Failed to remove %s: %v", info, err))
+	}
+	return nil
+}
+
+// restoreLinked replaces a hard link or symlink with an independent copy of its content.
+func restoreLinked(ctx context.Context, e JournalEntry) error {
+	info, err := os.Lstat(e.Path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFileChanged, err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if target, err := os.Readlink(e.Path); err != nil || target != e.Destination {
+			return fmt.Errorf("%w: symlink no longer points to %s", ErrFileChanged, e.Destination)
+		}
+	}
+	if err := verifyHash(ctx, e.Path, e.HashAlgorithm, e.Hash); err != nil {
+		return err
+	}
+
+	err = replaceAtomically(e.Path, func(tmp string) error {
+		return copyFile(ctx, e.Path, tmp)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore: %w", err)
+	}
+	return restoreModTime(e)
+}
+
+// ensureAbsent refuses to restore over a file that reappeared at path.
+func ensureAbsent(path string) error {
+	if _, err := os.Lstat(path); err == nil {
+		return fmt.Errorf("%w: %s", ErrDestinationExists, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// restoreModTime sets the modification time the duplicate had when it was scanned.
+func restoreModTime(e JournalEntry) error {
+	modTime, err := time.Parse(time.RFC3339, e.ModTime)
+	if err != nil {
+		return nil
+	}
+	return os.Chtimes(e.Path, modTime, modTime)
+}
@@ -0,0 +1,203 @@
+//go:build linux
+
+package actions
+
+import (
+	"DuDe/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const trashSupported = true
+
+// trashFile moves dup to the freedesktop.org Trash, following
+// https://specifications.freedesktop.org/trash-spec/latest/
+// The home trash is used when dup lives on the same filesystem,
+// otherwise $topdir/.Trash-$uid on dup's own filesystem.
+func trashFile(ctx context.Context, dup, original models.FileHash, req Request) (string, int64, error) {
+	src, err := filepath.Abs(dup.FilePath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	home, err := homeTrashDir()
+	if err != nil {
+		return "", 0, err
+	}
+
+	dest, err := trashInto(home, src)
+	if errors.Is(err, syscall.EXDEV) {
+		topdir, terr := mountPoint(src)
+		if terr != nil {
+			return "", 0, terr
+		}
+		dest, err = trashInto(filepath.Join(topdir, ".Trash-"+strconv.Itoa(os.Getuid())), src)
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to trash: %w", err)
+	}
+	return dest, dup.FileSize, nil
+}
+
+// homeTrashDir returns $XDG_DATA_HOME/Trash, defaulting to ~/.local/share/Trash.
+func homeTrashDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "Trash"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home trash: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "Trash"), nil
+}
+
+// trashInto moves src into trashDir. The .trashinfo file is created first
+// and claims the name, as the specification requires.
+func trashInto(trashDir, src string) (string, error) {
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return "", err
+		}
+	}
+
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: src}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+
+	base := filepath.Base(src)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(info)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return "", err
+		}
+
+		dest := filepath.Join(filesDir, name)
+		if _, err := os.Lstat(dest); err == nil {
+			// A stale entry without info file; leave it alone and pick another name.
+			os.Remove(infoPath)
+			continue
+		}
+		if err := os.Rename(src, dest); err != nil {
+			os.Remove(infoPath)
+			return "", err
+		}
+		return dest, nil
+	}
+}
+
+// findTrashed returns where trashFile moved path: the most recently trashed file whose
+// .trashinfo file names path, in the home trash or the one on path's filesystem.
+func findTrashed(path string) (string, error) {
+	src, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var trashDirs []string
+	if home, err := homeTrashDir(); err == nil {
+		trashDirs = append(trashDirs, home)
+	}
+	if topdir, err := mountPoint(src); err == nil {
+		trashDirs = append(trashDirs, filepath.Join(topdir, ".Trash-"+strconv.Itoa(os.Getuid())))
+	}
+
+	want := "Path=" + (&url.URL{Path: src}).EscapedPath()
+	var found, newest string
+	for _, trashDir := range trashDirs {
+		infos, err := os.ReadDir(filepath.Join(trashDir, "info"))
+		if err != nil {
+			continue
+		}
+		for _, info := range infos {
+			name, ok := strings.CutSuffix(info.Name(), ".trashinfo")
+			if !ok {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(trashDir, "info", info.Name()))
+			if err != nil {
+				continue
+			}
+			lines := strings.Split(string(content), "\n")
+			if !slices.Contains(lines, want) {
+				continue
+			}
+			file := filepath.Join(trashDir, "files", name)
+			if _, err := os.Lstat(file); err != nil {
+				continue
+			}
+			var deleted string
+			for _, line := range lines {
+				if date, ok := strings.CutPrefix(line, "DeletionDate="); ok {
+					deleted = date
+				}
+			}
+			if found == "" || deleted > newest {
+				found, newest = file, deleted
+			}
+		}
+	}
+	if found == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotInTrash, path)
+	}
+	return found, nil
+}
+
+// mountPoint returns the top directory of the filesystem containing path.
+func mountPoint(path string) (string, error) {
+	dev := func(p string) (uint64, error) {
+		var st syscall.Stat_t
+		if err := syscall.Lstat(p, &st); err != nil {
+			return 0, err
+		}
+		return uint64(st.Dev), nil
+	}
+
+	current := filepath.Dir(path)
+	currentDev, err := dev(current)
+	if err != nil {
+		return "", err
+	}
+	for {
+		parent := filepath.Dir(current)
+		if parent == current {
+			return current, nil
+		}
+		parentDev, err := dev(parent)
+		if err != nil {
+			return "", err
+		}
+		if parentDev != currentDev {
+			return current, nil
+		}
+		current = parent
+	}
+}
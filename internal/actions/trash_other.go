@@ -0,0 +1,18 @@
+//go:build !linux
+
+package actions
+
+import (
+	"DuDe/internal/models"
+	"context"
+)
+
+const trashSupported = false
+
+func trashFile(ctx context.Context, dup, original models.FileHash, req Request) (string, int64, error) {
+	return "", 0, ErrTrashUnsupported
+}
+
+func findTrashed(path string) (string, error) {
+	return "", ErrTrashUnsupported
+}
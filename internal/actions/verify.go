@@ -0,0 +1,47 @@
+package actions
+
+import (
+	"DuDe/internal/common"
+	"DuDe/internal/common/hashing"
+	"DuDe/internal/models"
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Verify checks that the file on disk still matches what the scan recorded:
+// same size, same modification time and same content hash.
+func Verify(ctx context.Context, fh models.FileHash) error {
+	info, err := os.Lstat(fh.FilePath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFileChanged, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: no longer a regular file", ErrFileChanged)
+	}
+	if info.Size() != fh.FileSize {
+		return fmt.Errorf("%w: size %d, expected %d", ErrFileChanged, info.Size(), fh.FileSize)
+	}
+	if modTime := info.ModTime().Format(time.RFC3339); !common.SameModTime(modTime, fh.ModTime) {
+		return fmt.Errorf("%w: modified %s, expected %s", ErrFileChanged, modTime, fh.ModTime)
+	}
+
+	return verifyHash(ctx, fh.FilePath, fh.HashAlgorithm, fh.Hash)
+}
+
+// verifyHash checks that the content of path still hashes to hash.
+func verifyHash(ctx context.Context, path, algorithm, hash string) error {
+	hasher, err := hashing.Get(algorithm)
+	if err != nil {
+		return err
+	}
+	actual, err := hashing.HashFile(ctx, hasher, path, nil)
+	if err != nil {
+		return err
+	}
+	if actual != hash {
+		return fmt.Errorf("%w: content hash differs", ErrFileChanged)
+	}
+	return nil
+}
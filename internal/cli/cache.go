@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"DuDe/internal/common"
+	database "DuDe/internal/db"
+	"DuDe/internal/results"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+)
+
+// runCache implements `dude cache [flags] stats|prune|vacuum|export FILE|import FILE`,
+// with the flags before or after the command.
+func runCache(args []string, stdout, stderr io.Writer) int {
+	var req database.PruneRequest
+	var cacheDir, root string
+	var oldest int
+
+	flags := flag.NewFlagSet("cache", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: dude cache [flags] stats|prune|vacuum|export FILE|import FILE")
+		fmt.Fprintln(stderr, "stats describes the hash cache, prune removes the entries the flags select, vacuum shrinks the file,")
+		fmt.Fprintln(stderr, "export and import share it with another machine. Flags may also follow the command.")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&cacheDir, "cache-dir", "", "directory of the hash cache (default: executable directory)")
+	flags.IntVar(&oldest, "oldest", 10, "stats: number of entries seen longest ago to list")
+	flags.BoolVar(&req.Missing, "missing", false, "prune: entries of files that no longer exist")
+	flags.IntVar(&req.OlderThanDays, "older-than", 0, "prune: entries of files no scan walked for this many days")
+	flags.Func("prefix", "prune: entries of files below this directory, repeatable", func(dir string) error {
+		// The cache stores absolute paths, like -root is compared against.
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		req.Prefixes = append(req.Prefixes, dir)
+		return nil
+	})
+	flags.BoolVar(&req.Vacuum, "vacuum", false, "prune: vacuum the cache afterwards")
+	flags.StringVar(&root, "root", "", "export: only the files below this directory, relative to it; import: the directory a relative export is imported below")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitError
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return ExitError
+	}
+	// Parsing stops at the command, so the flags following it are parsed again.
+	sub := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitError
+	}
+	wantArgs := 0
+	if sub == "export" || sub == "import" {
+		wantArgs = 1
+	}
+	if flags.NArg() != wantArgs {
+		flags.Usage()
+		return ExitError
+	}
+	if root != "" {
+		var err error
+		if root, err = filepath.Abs(root); err != nil {
+			fmt.Fprintf(stderr, "Invalid root: %v\n", err)
+			return ExitError
+		}
+	}
+
+	if cacheDir == "" {
+		cacheDir = common.GetSafeResultsDir(runtime.GOOS)
+	}
+	open := database.OpenCache
+	if sub == "import" {
+		open = database.InitializeDatabase // the first import creates the cache
+	}
+	db, err := open(cacheDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to open cache: %v\n", err)
+		return ExitError
+	}
+	defer db.Close()
+
+	switch sub {
+	case "stats":
+		stats, err := database.Stats(db, cacheDir, oldest)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to read cache: %v\n", err)
+			return ExitError
+		}
+		printCacheStats(stdout, stats)
+
+	case "prune":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		report, err := database.Prune(ctx, db, cacheDir, req)
+		if err != nil {
+			fmt.Fprintf(stderr, "Prune failed: %v\n", err)
+			return ExitError
+		}
+		fmt.Fprintf(stdout, "Removed %d entries of missing files, %d not seen for %d days and %d by prefix; %d entries left.\n",
+			report.Missing, report.Old, req.OlderThanDays, report.Prefixed, report.EntriesLeft)
+		fmt.Fprintf(stdout, "Cache size: %s -> %s\n", results.HumanBytes(report.SizeBefore), results.HumanBytes(report.SizeAfter))
+
+	case "vacuum":
+		if err := database.Vacuum(db); err != nil {
+			fmt.Fprintf(stderr, "Vacuum failed: %v\n", err)
+			return ExitError
+		}
+		stats, err := database.Stats(db, cacheDir, 0)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to read cache: %v\n", err)
+			return ExitError
+		}
+		printCacheStats(stdout, stats)
+
+	case "export":
+		n, err := database.ExportCacheFile(db, flags.Arg(0), root)
+		if err != nil {
+			fmt.Fprintf(stderr, "Export failed: %v\n", err)
+			return ExitError
+		}
+		fmt.Fprintf(stdout, "Exported %d entries to %s.\n", n, flags.Arg(0))
+
+	case "import":
+		report, err := database.ImportCacheFile(db, flags.Arg(0), root)
+		if err != nil {
+			fmt.Fprintf(stderr, "Import failed: %v\n", err)
+			return ExitError
+		}
+		fmt.Fprintf(stdout, "Imported %d entries, skipped %d already cached.\n", report.Imported, report.Skipped)
+
+	default:
+		fmt.Fprintf(stderr, "unknown cache command %q\n", sub)
+		flags.Usage()
+		return ExitError
+	}
+	return ExitOK
+}
+
+// printCacheStats prints the statistics of the hash cache, with its oldest entries if any.
+func printCacheStats(stdout io.Writer, stats *database.CacheStats) {
+	fmt.Fprintf(stdout, "%s: %d entries, %s on disk, schema version %d\n",
+		stats.Path, stats.Entries, results.HumanBytes(stats.SizeBytes), stats.SchemaVersion)
+	if len(stats.Oldest) == 0 {
+		return
+	}
+	fmt.Fprintln(stdout, "Seen longest ago:")
+	for _, entry := range stats.Oldest {
+		fmt.Fprintf(stdout, "  %s  %s\n", entry.SeenAt, entry.Path)
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK         = 0 // command succeeded, no duplicates found
+	ExitDuplicates = 1 // scan succeeded and duplicates were found
+	ExitError      = 2 // invalid usage or execution failure
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{name: "scan", usage: "scan directories for duplicate files", run: runScan},
+	{name: "restore", usage: "undo a delete, quarantine, trash or link action", run: runRestore},
+	{name: "cache", usage: "show statistics of the hash cache, prune, vacuum, export or import it", run: runCache},
+}
+
+// IsCommand reports whether name is a known CLI sub-command.
+// main uses it to decide between the headless CLI and the Wails GUI.
+func IsCommand(name string) bool {
+	for _, c := range commands {
+		if c.name == name {
+			return true
+		}
+	}
+	return name == "help" || name == "-h" || name == "--help"
+}
+
+// Run executes the sub-command named in args[0] and returns the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return ExitError
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return ExitOK
+	}
+
+	fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+	printUsage(stderr)
+	return ExitError
+}
+
+func printUsage(w io.Writer) {
+	var b strings.Builder
+	b.WriteString("Usage: dude <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "  %-10s %s\n", c.name, c.usage)
+	}
+	b.WriteString("\nRun 'dude <command> -h' for the flags of a command.\n")
+	fmt.Fprint(w, b.String())
+}
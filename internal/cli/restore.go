@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"DuDe/internal/actions"
+	"DuDe/internal/common"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+)
+
+// runRestore implements `dude restore [flags] [SESSION]`.
+func runRestore(args []string, stdout, stderr io.Writer) int {
+	var req actions.RestoreRequest
+	var cacheDir string
+	var list bool
+
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: dude restore [flags] [SESSION]")
+		fmt.Fprintln(stderr, "Restores the most recent session if SESSION is omitted.")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&cacheDir, "cache-dir", "", "directory of the hash cache and the undo journal (default: executable directory)")
+	flags.StringVar(&req.Group, "group", "", "only restore the duplicates of the group with this original path")
+	flags.BoolVar(&list, "list", false, "list the journaled sessions instead of restoring")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitError
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return ExitError
+	}
+	req.Session = flags.Arg(0)
+
+	if cacheDir == "" {
+		cacheDir = common.GetSafeResultsDir(runtime.GOOS)
+	}
+
+	if list {
+		sessions, err := actions.Sessions(cacheDir)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to read journal: %v\n", err)
+			return ExitError
+		}
+		for _, s := range sessions {
+			fmt.Fprintf(stdout, "%s  %-10s %d files, %d restored (%s)\n", s.ID, s.Action, s.Files, s.Restored, s.Started)
+		}
+		return ExitOK
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := actions.RestoreFromJournal(ctx, cacheDir, req)
+	if err != nil {
+		fmt.Fprintf(stderr, "Restore failed: %v\n", err)
+		return ExitError
+	}
+
+	for _, o := range report.Outcomes {
+		if !o.Succeeded() {
+			fmt.Fprintf(stderr, "restore %s: %s\n", o.Path, o.Error)
+		}
+	}
+	fmt.Fprintf(stdout, "Restored %d files of session %s, %d failed.\n", report.FilesActed, report.Session, report.FilesFailed)
+	if report.FilesFailed > 0 {
+		return ExitError
+	}
+	return ExitOK
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"DuDe/internal/actions"
+	"DuDe/internal/common"
+	"DuDe/internal/common/fs"
+	"DuDe/internal/common/hashing"
+	"DuDe/internal/filters"
+	"DuDe/internal/handlers/validation"
+	"DuDe/internal/keeprules"
+	"DuDe/internal/models"
+	"DuDe/internal/processing"
+	"DuDe/internal/reporting"
+	"DuDe/internal/results"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+)
+
+// runScan implements `dude scan [flags] DIR...`.
+func runScan(args []string, stdout, stderr io.Writer) int {
+	var params models.ExecutionParams
+	var action actions.Request
+	var formats, extensions, excludeExtensions string
+	var quiet bool
+
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: dude scan [flags] DIR...")
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&params.UseCache, "cache", true, "use the hash cache (memory.db)")
+	flags.StringVar(&params.CacheDir, "cache-dir", "", "directory of the hash cache (default: executable directory)")
+	flags.StringVar(&params.ResultsDir, "results-dir", "", "directory for the results file (default: executable directory)")
+	flags.BoolVar(&params.ParanoidMode, "paranoid", false, "verify duplicates byte-by-byte")
+	flags.IntVar(&params.CPUs, "cpus", 0, "number of hashing workers (default: all CPUs)")
+	flags.IntVar(&params.BufSize, "buf-size", 0, "size of the cache write buffer")
+	flags.StringVar(&params.HashAlgorithm, "hash", hashing.Default, "hash algorithm: "+strings.Join(hashing.Algorithms(), ", "))
+	flags.IntVar(&params.PartialHashKiB, "partial-kib", 0, "KiB hashed from both ends of a file before full hashing (default 64)")
+	flags.Func("keep", "keep rule choosing the original of each group, repeatable, evaluated in order: "+strings.Join(keeprules.Kinds(), ", "),
+		func(rule string) error {
+			params.KeepRules = append(params.KeepRules, rule)
+			return nil
+		})
+	flags.Func("include", "glob of files to scan, repeatable (default: every file)", func(pattern string) error {
+		params.Include = append(params.Include, pattern)
+		return nil
+	})
+	flags.Func("exclude", "glob of files and directories to skip, repeatable, e.g. node_modules or *.tmp", func(pattern string) error {
+		params.Exclude = append(params.Exclude, pattern)
+		return nil
+	})
+	flags.Func("reference", "read-only reference directory, repeatable: only copies of its files elsewhere are duplicates, and they are never acted on",
+		func(dir string) error {
+			params.ReferenceDirs = append(params.ReferenceDirs, dir)
+			return nil
+		})
+	flags.StringVar(&extensions, "ext", "", "comma-separated extensions to scan, e.g. jpg,png (default: every extension)")
+	flags.StringVar(&excludeExtensions, "exclude-ext", "", "comma-separated extensions to skip")
+	flags.Int64Var(&params.MinSize, "min-size", 0, "skip files smaller than this many bytes")
+	flags.Int64Var(&params.MaxSize, "max-size", 0, "skip files larger than this many bytes (default: no limit)")
+	flags.StringVar(&params.ModifiedAfter, "modified-after", "", "only scan files modified at or after this time (2006-01-02 or RFC 3339)")
+	flags.StringVar(&params.ModifiedBefore, "modified-before", "", "only scan files modified before this time (2006-01-02 or RFC 3339)")
+	flags.BoolVar(&params.RespectIgnoreFiles, "ignore-files", false, "skip what .gitignore and .dudeignore files in the scanned trees ignore")
+	flags.BoolVar(&params.ScanVCSDirs, "scan-vcs", false, "also scan .git, .svn and .hg directories")
+	flags.StringVar(&params.SymlinkPolicy, "symlinks", filters.DefaultSymlinkPolicy, "how to treat symlinks: "+strings.Join(filters.SymlinkPolicies(), ", "))
+	flags.BoolVar(&params.CompareDirs, "dirs", false, "also report directories identical to or contained in another one")
+	flags.BoolVar(&params.ReportHardLinks, "hard-links", false, "list files found under several paths because of hard links; they are never duplicates")
+	flags.StringVar(&formats, "format", results.Default, "comma-separated result formats: "+strings.Join(results.Formats(), ", "))
+	flags.StringVar(&params.CSVDelimiter, "csv-delimiter", ",", "single character separating CSV fields, or \"tab\"")
+	flags.BoolVar(&params.CSVOmitBOM, "csv-no-bom", false, "do not start the CSV file with a UTF-8 BOM")
+	flags.IntVar(&params.CSVLayout, "csv-layout", results.DefaultCSVLayout, "CSV columns: 1 original/duplicate pairs, 2 one row per file")
+	flags.BoolVar(&params.DebugMode, "debug", false, "write a debug log next to the executable")
+	flags.StringVar(&action.Action, "action", "", "act on the duplicates found, keeping one original per group: "+strings.Join(actions.Names(), ", "))
+	flags.StringVar(&action.QuarantineDir, "quarantine-dir", "", "destination of -action quarantine")
+	flags.BoolVar(&action.DryRun, "dry-run", false, "verify and report what -action would do without touching any file")
+	flags.BoolVar(&quiet, "quiet", false, "do not print progress")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitError
+	}
+	params.Directories = flags.Args()
+	params.ResultFormats = strings.Split(formats, ",")
+	params.Extensions = strings.Split(extensions, ",")
+	params.ExcludeExtensions = strings.Split(excludeExtensions, ",")
+
+	if action.Action != "" {
+		if err := action.Validate(); err != nil {
+			fmt.Fprintf(stderr, "Argument Validation Failed: %v\n", err)
+			return ExitError
+		}
+	}
+
+	resolver := validation.Resolver{
+		V: validation.Validator{
+			FS: fs.OS{},
+		},
+	}
+	if err := resolver.ResolveAndValidateArgs(&params, common.GetSafeResultsDir(runtime.GOOS)); err != nil {
+		fmt.Fprintf(stderr, "Argument Validation Failed: %v\n", err)
+		return ExitError
+	}
+
+	progressOut := stderr
+	if quiet {
+		progressOut = io.Discard
+	}
+	reporter := reporting.NewConsoleReporter(progressOut)
+
+	// Ctrl+C cancels the execution the same way the GUI Cancel button does.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := processing.Execute(ctx, params, reporter)
+	if err != nil {
+		fmt.Fprintf(stderr, "Execution failed: %v\n", err)
+		return ExitError
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(stderr, "Warning: %s\n", warning)
+	}
+
+	if params.ReportHardLinks {
+		printHardLinks(stdout, result.HardLinks)
+	}
+	if params.SymlinkPolicy == filters.SymlinkReport {
+		printSymlinks(stdout, result.Symlinks)
+	}
+
+	if !result.HasDuplicates() {
+		fmt.Fprintf(stdout, "No duplicates found in %d files.\n", result.FilesFound)
+		return ExitOK
+	}
+
+	fmt.Fprintf(stdout, "Found %d duplicate groups in %d files. Results written to %s\n",
+		len(result.Groups), result.FilesFound, strings.Join(result.ResultFiles, ", "))
+	printSummary(stdout, result.Summary)
+	if params.CompareDirs {
+		printDirectories(stdout, result.DuplicateDirs, result.SubsetDirs)
+	}
+
+	if action.Action == "" {
+		return ExitDuplicates
+	}
+
+	action.ReferenceDirs = params.ReferenceDirs
+	action.JournalDir = params.CacheDir
+	report, err := actions.Apply(ctx, result.Groups, action)
+	if err != nil {
+		fmt.Fprintf(stderr, "Action failed: %v\n", err)
+		return ExitError
+	}
+	printReport(stdout, stderr, report)
+	if report.FilesFailed > 0 {
+		return ExitError
+	}
+	return ExitDuplicates
+}
+
+// printHardLinks prints every file walked under several paths, one path per line.
+func printHardLinks(stdout io.Writer, sets []models.FileHash) {
+	fmt.Fprintf(stdout, "%d files have several hard links (not duplicates):\n", len(sets))
+	for _, set := range sets {
+		fmt.Fprintf(stdout, "  %s\n", set.FilePath)
+		for _, link := range set.HardLinks {
+			fmt.Fprintf(stdout, "    = %s\n", link)
+		}
+	}
+}
+
+// printSymlinks prints every symlink found with its target.
+func printSymlinks(stdout io.Writer, links []results.Symlink) {
+	fmt.Fprintf(stdout, "%d symlinks (not scanned):\n", len(links))
+	for _, link := range links {
+		broken := ""
+		if link.Broken {
+			broken = " (broken)"
+		}
+		fmt.Fprintf(stdout, "  %s -> %s%s\n", link.Path, link.Target, broken)
+	}
+}
+
+// printDirectories prints the sets of identical directories and the subset directories.
+func printDirectories(stdout io.Writer, sets []results.DirectorySet, subsets []results.DirectorySubset) {
+	fmt.Fprintf(stdout, "%d sets of identical directories:\n", len(sets))
+	for _, set := range sets {
+		fmt.Fprintf(stdout, "  %d files, %s each\n", set.Files, results.HumanBytes(set.Size))
+		for _, path := range set.Paths {
+			fmt.Fprintf(stdout, "    %s\n", path)
+		}
+	}
+	fmt.Fprintf(stdout, "%d directories contained in another one:\n", len(subsets))
+	for _, subset := range subsets {
+		fmt.Fprintf(stdout, "  %s (%d files) in %s\n", subset.Path, subset.Files, subset.Superset)
+	}
+}
+
+// summaryRows is the number of rows printed per breakdown; the results files have all of them.
+const summaryRows = 10
+
+// printSummary prints the totals of the scan followed by the largest groups and the
+// extensions and directories with the most reclaimable space.
+func printSummary(stdout io.Writer, s results.ScanSummary) {
+	fmt.Fprintf(stdout, "Scanned %d files (%s). %d duplicate files in %d groups, %s reclaimable.\n",
+		s.FilesScanned, results.HumanBytes(s.BytesScanned), s.DuplicateFiles, s.DuplicateGroups, results.HumanBytes(s.ReclaimableBytes))
+
+	if len(s.LargestGroups) > 0 {
+		fmt.Fprintln(stdout, "Largest groups:")
+		for _, g := range s.LargestGroups {
+			fmt.Fprintf(stdout, "  %10s  %d files  %s\n", results.HumanBytes(g.ReclaimableBytes), g.Files, g.Original)
+		}
+	}
+	printBreakdown(stdout, "By extension:", s.ByExtension)
+	printBreakdown(stdout, "By directory:", s.ByDirectory)
+}
+
+func printBreakdown(stdout io.Writer, title string, rows []results.Breakdown) {
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Fprintln(stdout, title)
+	for i, b := range rows {
+		if i == summaryRows {
+			fmt.Fprintf(stdout, "  ... %d more\n", len(rows)-summaryRows)
+			break
+		}
+		fmt.Fprintf(stdout, "  %10s  %d files  %s\n", results.HumanBytes(b.ReclaimableBytes), b.DuplicateFiles, b.Key)
+	}
+}
+
+// printReport prints one line per failed file to stderr and a summary to stdout.
+func printReport(stdout, stderr io.Writer, report *actions.Report) {
+	for _, o := range report.Outcomes {
+		if !o.Succeeded() {
+			fmt.Fprintf(stderr, "%s %s: %s\n", report.Action, o.Path, o.Error)
+		}
+	}
+
+	verb := "Applied"
+	if report.DryRun {
+		verb = "Dry run:"
+	}
+	fmt.Fprintf(stdout, "%s %s to %d files (%d bytes reclaimed), %d failed.\n",
+		verb, report.Action, report.FilesActed, report.BytesFreed, report.FilesFailed)
+	if report.Session != "" {
+		fmt.Fprintf(stdout, "Undo with: dude restore %s\n", report.Session)
+	}
+}
@@ -0,0 +1,44 @@
+package ctxio
+
+import (
+	"context"
+	"io"
+)
+
+// ChunkSize is the largest amount of data read between two cancellation checks.
+const ChunkSize = 256 * 1024
+
+// reader wraps an io.Reader so that every Read first checks the context,
+// which bounds how long a cancelled read of a very large file keeps running.
+// onRead, when set, is called with the number of bytes of every successful read.
+type reader struct {
+	ctx    context.Context
+	r      io.Reader
+	onRead func(n int64)
+}
+
+// NewReader returns a reader that fails with the context error once ctx is done.
+func NewReader(ctx context.Context, r io.Reader, onRead func(n int64)) io.Reader {
+	return &reader{ctx: ctx, r: r, onRead: onRead}
+}
+
+func (cr *reader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(p) > ChunkSize {
+		p = p[:ChunkSize]
+	}
+	n, err := cr.r.Read(p)
+	if n > 0 && cr.onRead != nil {
+		cr.onRead(int64(n))
+	}
+	return n, err
+}
+
+// Copy copies src to dst in chunks of at most ChunkSize bytes,
+// stopping with the context error as soon as ctx is cancelled.
+func Copy(ctx context.Context, dst io.Writer, src io.Reader, onRead func(n int64)) (int64, error) {
+	buf := make([]byte, ChunkSize)
+	return io.CopyBuffer(dst, NewReader(ctx, src, onRead), buf)
+}
@@ -0,0 +1,99 @@
+package hashing
+
+import (
+	"DuDe/internal/common/ctxio"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/blake3"
+)
+
+// Supported hash algorithms. The names are persisted in the cache database,
+// so they must never change.
+const (
+	MD5    = "md5"
+	XXHash = "xxhash"
+	SHA256 = "sha256"
+	BLAKE3 = "blake3"
+
+	// Default keeps caches created before the algorithm was selectable valid.
+	Default = MD5
+)
+
+var ErrUnknownAlgorithm = errors.New("unknown hash algorithm")
+
+// Hasher produces content hashes with a single, named algorithm.
+type Hasher interface {
+	// Algorithm returns the name stored alongside every hash.
+	Algorithm() string
+	// New returns a fresh hash.Hash, one per file.
+	New() hash.Hash
+}
+
+type hasher struct {
+	name    string
+	newFunc func() hash.Hash
+}
+
+func (h hasher) Algorithm() string { return h.name }
+func (h hasher) New() hash.Hash    { return h.newFunc() }
+
+var hashers = map[string]Hasher{
+	MD5:    hasher{name: MD5, newFunc: md5.New},
+	XXHash: hasher{name: XXHash, newFunc: func() hash.Hash { return xxhash.New() }},
+	SHA256: hasher{name: SHA256, newFunc: sha256.New},
+	BLAKE3: hasher{name: BLAKE3, newFunc: func() hash.Hash { return blake3.New() }},
+}
+
+// Algorithms returns the names of all supported algorithms.
+func Algorithms() []string {
+	return []string{MD5, XXHash, SHA256, BLAKE3}
+}
+
+// Normalize lower-cases and trims the algorithm name and applies the default for "".
+func Normalize(algorithm string) string {
+	algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+	if algorithm == "" {
+		return Default
+	}
+	return algorithm
+}
+
+// Get returns the Hasher for the given algorithm name.
+func Get(algorithm string) (Hasher, error) {
+	h, ok := hashers[Normalize(algorithm)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (supported: %s)", ErrUnknownAlgorithm, algorithm, strings.Join(Algorithms(), ", "))
+	}
+	return h, nil
+}
+
+// HashFile hashes the whole file at path, checking ctx between chunks.
+// onRead, when set, receives the number of bytes read.
+func HashFile(ctx context.Context, hasher Hasher, path string, onRead func(n int64)) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := hasher.New()
+	if _, err := ctxio.Copy(ctx, h, f, onRead); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -5,7 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sync"
+	"time"
 )
 
 func GetExecutableDir() string {
@@ -51,18 +51,20 @@ func GetOpenDirectoryFunc(path, platform string) (*exec.Cmd, error) {
 	}
 }
 
+// SameModTime reports whether two RFC 3339 modification times are the same instant, so
+// times recorded in another time zone still match.
+func SameModTime(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	return errA == nil && errB == nil && ta.Equal(tb)
+}
+
 func Must[T any](value T, err error) T {
 	if err != nil {
 		panic(err)
 	}
 	return value
 }
-
-func LenSyncMap(m *sync.Map) int {
-	var count int
-	m.Range(func(key, value interface{}) bool {
-		count++
-		return true
-	})
-	return count
-}
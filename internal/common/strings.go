@@ -6,6 +6,7 @@ const (
 	Results_file_name      = "results"
 	Results_file_extension = "csv"
 	MemFilename            = "memory.db"
+	JournalFilename        = "actions.journal"
 
 	ResultsFileSeperator = "------"
 
@@ -33,5 +34,10 @@ const (
 )
 
 var (
+	// ResultsHeader is the header of the legacy CSV layout (1): one row per original/duplicate pair.
 	ResultsHeader = []string{"File Name", "Path", "Duplicate File Name", "Duplicate Path"}
+	// ResultsHeaderV2 is the header of CSV layout 2: one row per file, no separator rows.
+	ResultsHeaderV2 = []string{"Group ID", "Role", "File Name", "Path", "Size", "Modified", "Hash", "Hash Algorithm"}
+	// SummaryHeader is the header of the CSV summary written next to the results.
+	SummaryHeader = []string{"Section", "Name", "Files", "Bytes"}
 )
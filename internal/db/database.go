@@ -3,56 +3,57 @@ package db
 import (
 	"DuDe/internal/common"
 	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
 	_ "modernc.org/sqlite"
 )
 
+// pragmas apply to every connection: WAL lets readers run alongside the batched writes,
+// which then only need a full sync at checkpoints, and a locked cache is waited for
+// rather than failed on.
+const pragmas = "?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=busy_timeout(5000)"
+
 // InitializeDatabase returns a new database connection
 func InitializeDatabase(dir string) (*sql.DB, error) {
 	dbpath := filepath.Join(dir, common.MemFilename)
 
-	db, err := sql.Open("sqlite", dbpath)
+	db, err := sql.Open("sqlite", dbpath+pragmas)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = AutoMigrate(db); err != nil {
-		db.Close() // Close the db if automigration fails.
+	if err = Migrate(db); err != nil {
+		db.Close() // Close the db if a migration fails.
 		return nil, err
 	}
 
 	return db, nil
 }
-func GetDatabaseConnection(dir string) (*sql.DB, error) {
-	dbpath := filepath.Join(dir, common.MemFilename)
 
-	db, err := sql.Open("sqlite", dbpath)
-	if err != nil {
-		return nil, err
+// ErrNoCache is returned by OpenCache for a directory without a hash cache.
+var ErrNoCache = errors.New("no hash cache found")
+
+// OpenCache opens the existing hash cache in dir, upgrading its schema, without creating
+// one where there is none.
+func OpenCache(dir string) (*sql.DB, error) {
+	dbpath := filepath.Join(dir, common.MemFilename)
+	if _, err := os.Stat(dbpath); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w in %s", ErrNoCache, dir)
 	}
-	return db, nil
+	return InitializeDatabase(dir)
 }
 
-// AutoMigrate creates the database schema based on models
-func AutoMigrate(db *sql.DB) error {
-	_, err := db.Exec(`
-                CREATE TABLE IF NOT EXISTS file_hashes (
-                        id INTEGER PRIMARY KEY AUTOINCREMENT,
-                        path TEXT UNIQUE,
-                        hash TEXT,
-                        size INTEGER,
-                        modified_time TEXT,
-						updated_at TEXT,
-						created_at TEXT
-                )
-        `)
+func GetDatabaseConnection(dir string) (*sql.DB, error) {
+	dbpath := filepath.Join(dir, common.MemFilename)
 
+	db, err := sql.Open("sqlite", dbpath+pragmas)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return db, nil
 }
 
 func TruncateDatabase(db *sql.DB) error {
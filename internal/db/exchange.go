@@ -0,0 +1,185 @@
+package db
+
+import (
+	"DuDe/internal/models/db_models"
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Errors returned by ImportCache.
+var (
+	ErrNotAnExport   = errors.New("not a DuDe cache export")
+	ErrExportVersion = errors.New("unsupported cache export version")
+	ErrImportRoot    = errors.New("a root directory is required to import relative paths")
+	ErrAbsoluteRoot  = errors.New("the export holds absolute paths, it cannot be re-rooted")
+	ErrOutsideRoot   = errors.New("path leaves the import root")
+)
+
+const (
+	exportFormat  = "dude-cache"
+	exportVersion = 1
+)
+
+// exportHeader is the first line of an export.
+type exportHeader struct {
+	Format   string `json:"format"`
+	Version  int    `json:"version"`
+	Relative bool   `json:"relative"` // paths are slash-separated and relative to the exported root
+	Exported string `json:"exported"` // RFC 3339
+}
+
+// exportEntry is every further line of an export.
+type exportEntry struct {
+	Path          string `json:"path"`
+	Hash          string `json:"hash"`
+	HashAlgorithm string `json:"hashAlgorithm"`
+	PartialHash   string `json:"partialHash"`
+	PartialWindow int64  `json:"partialWindow"` // 0 in exports from before it was recorded
+	Size          int64  `json:"size"`
+	ModTime       string `json:"modTime"`
+}
+
+// ImportReport tells how many entries ImportCache added to the cache.
+type ImportReport struct {
+	Imported int64 `json:"imported"` // new entries, trusted once a scan finds the file unchanged
+	Skipped  int64 `json:"skipped"`  // entries of files the cache already had; its own entry is kept
+}
+
+// ExportCache writes the cache entries to w, one JSON object per line after a header.
+// With a root only the files below it are exported, with paths relative to it, so the
+// export can be imported under another root on another machine.
+func ExportCache(db *sql.DB, w io.Writer, root string) (int64, error) {
+	repo := NewFileHashRepository(db)
+	var records []*db_models.FileHash
+	var err error
+	if root == "" {
+		records, err = repo.GetAll()
+	} else {
+		records, err = repo.GetUnder(root)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	header := exportHeader{Format: exportFormat, Version: exportVersion, Relative: root != "", Exported: time.Now().UTC().Format(time.RFC3339)}
+	if err := enc.Encode(header); err != nil {
+		return 0, err
+	}
+
+	var exported int64
+	for _, r := range records {
+		// Entries written by the partial hash stage carry no full hash and are not worth sharing.
+		if r.Hash == "" {
+			continue
+		}
+		path := r.FilePath
+		if root != "" {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return exported, err
+			}
+			path = filepath.ToSlash(rel)
+		}
+		if err := enc.Encode(exportEntry{Path: path, Hash: r.Hash, HashAlgorithm: r.HashAlgorithm, PartialHash: r.PartialHash, PartialWindow: r.PartialWindow, Size: r.FileSize, ModTime: r.ModTime}); err != nil {
+			return exported, err
+		}
+		exported++
+	}
+	return exported, bw.Flush()
+}
+
+// ImportCache adds the entries of an export read from r to the cache, below root for an
+// export with relative paths. Entries of files the cache already has are skipped. Imported
+// entries are marked as such: a scan only uses one after checking the file's size,
+// modification time and partial hash, taken with the same window, and then saves it as its own.
+func ImportCache(db *sql.DB, r io.Reader, root string) (*ImportReport, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var header exportHeader
+	if err := dec.Decode(&header); err != nil || header.Format != exportFormat {
+		return nil, ErrNotAnExport
+	}
+	if header.Version != exportVersion {
+		return nil, fmt.Errorf("%w: %d", ErrExportVersion, header.Version)
+	}
+	if header.Relative && root == "" {
+		return nil, ErrImportRoot
+	}
+	if !header.Relative && root != "" {
+		return nil, ErrAbsoluteRoot
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO file_hashes (path, hash, hash_algorithm, partial_hash, partial_window, size, modified_time, imported, created_at, seen_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT(path) DO NOTHING`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	report := &ImportReport{}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for line := 2; ; line++ {
+		var entry exportEntry
+		if err := dec.Decode(&entry); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		path := entry.Path
+		if header.Relative {
+			if !filepath.IsLocal(filepath.FromSlash(path)) {
+				return nil, fmt.Errorf("line %d (%q): %w", line, path, ErrOutsideRoot)
+			}
+			path = filepath.Join(root, filepath.FromSlash(path))
+		}
+		result, err := stmt.Exec(path, entry.Hash, entry.HashAlgorithm, entry.PartialHash, entry.PartialWindow, entry.Size, entry.ModTime, now, now)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			report.Skipped++
+		} else {
+			report.Imported++
+		}
+	}
+	return report, tx.Commit()
+}
+
+// ExportCacheFile writes the export of ExportCache to the file at path, replacing it.
+func ExportCacheFile(db *sql.DB, path, root string) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := ExportCache(db, f, root)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// ImportCacheFile imports the export in the file at path with ImportCache.
+func ImportCacheFile(db *sql.DB, path, root string) (*ImportReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ImportCache(db, f, root)
+}
@@ -11,8 +11,12 @@ import (
 type FileHashRepo interface {
 	GetByPath(path string) (*db_models.FileHash, error)
 	GetAll() ([]*db_models.FileHash, error)
+	GetUnder(dir string) ([]*db_models.FileHash, error)
 	Create(fh *db_models.FileHash) error
 	Update(fh *db_models.FileHash) error
+	Upsert(fh *db_models.FileHash) error
+	UpsertBatch(fhs []*db_models.FileHash) error
+	MarkSeen(paths []string) error
 	Delete(id int) error
 	DeleteByPath(path string) error
 }
@@ -25,9 +29,23 @@ func NewFileHashRepository(db *sql.DB) *FileHashRepository {
 	return &FileHashRepository{Db: db}
 }
 
+const selectColumns = `SELECT id, path, hash, hash_algorithm, partial_hash, partial_window, size, modified_time, imported, created_at FROM file_hashes`
+
 func (r *FileHashRepository) GetAll() ([]*db_models.FileHash, error) {
+	return r.query(selectColumns)
+}
+
+// GetUnder returns the records of the files below dir, at any depth. The range on path
+// is answered by the index of its UNIQUE constraint, so a small directory is cheap to
+// load from a large cache.
+func (r *FileHashRepository) GetUnder(dir string) ([]*db_models.FileHash, error) {
+	start, end := pathRange(dir)
+	return r.query(selectColumns+` WHERE path >= ? AND path < ?`, start, end)
+}
+
+func (r *FileHashRepository) query(query string, args ...any) ([]*db_models.FileHash, error) {
 	var filehashes []*db_models.FileHash
-	rows, err := r.Db.Query(`SELECT id, path, hash, size, modified_time, created_at FROM file_hashes`)
+	rows, err := r.Db.Query(query, args...)
 
 	if err != nil {
 		return nil, err
@@ -37,7 +55,7 @@ func (r *FileHashRepository) GetAll() ([]*db_models.FileHash, error) {
 
 	for rows.Next() {
 		filehash := &db_models.FileHash{}
-		if err := rows.Scan(&filehash.ID, &filehash.FilePath, &filehash.Hash, &filehash.FileSize, &filehash.ModTime, &filehash.CreatedAt); err != nil {
+		if err := rows.Scan(&filehash.ID, &filehash.FilePath, &filehash.Hash, &filehash.HashAlgorithm, &filehash.PartialHash, &filehash.PartialWindow, &filehash.FileSize, &filehash.ModTime, &filehash.Imported, &filehash.CreatedAt); err != nil {
 			return nil, err
 		}
 		filehashes = append(filehashes, filehash)
@@ -52,8 +70,8 @@ func (r *FileHashRepository) GetAll() ([]*db_models.FileHash, error) {
 
 func (r *FileHashRepository) GetByPath(path string) (*db_models.FileHash, error) {
 	filehash := &db_models.FileHash{}
-	row := r.Db.QueryRow("SELECT id, path, hash, size, modified_time, updated_at, created_at FROM file_hashes WHERE path = ?", path)
-	err := row.Scan(&filehash.ID, &filehash.FilePath, &filehash.Hash, &filehash.FileSize, &filehash.ModTime, &filehash.UpdatedAt, &filehash.CreatedAt)
+	row := r.Db.QueryRow("SELECT id, path, hash, hash_algorithm, partial_hash, partial_window, size, modified_time, updated_at, created_at FROM file_hashes WHERE path = ?", path)
+	err := row.Scan(&filehash.ID, &filehash.FilePath, &filehash.Hash, &filehash.HashAlgorithm, &filehash.PartialHash, &filehash.PartialWindow, &filehash.FileSize, &filehash.ModTime, &filehash.UpdatedAt, &filehash.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.New("no record found")
@@ -64,8 +82,8 @@ func (r *FileHashRepository) GetByPath(path string) (*db_models.FileHash, error)
 }
 
 func (r *FileHashRepository) Create(fh *db_models.FileHash) error {
-	result, err := r.Db.Exec("INSERT INTO file_hashes (path, hash, size, modified_time, created_at) VALUES (?, ?, ?, ?, ?)",
-		fh.FilePath, fh.Hash, fh.FileSize, fh.ModTime, time.Now().UTC().Format(time.RFC3339))
+	result, err := r.Db.Exec("INSERT INTO file_hashes (path, hash, hash_algorithm, partial_hash, partial_window, size, modified_time, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		fh.FilePath, fh.Hash, fh.HashAlgorithm, fh.PartialHash, fh.PartialWindow, fh.FileSize, fh.ModTime, time.Now().UTC().Format(time.RFC3339))
 
 	if err != nil {
 		return err
@@ -95,6 +113,9 @@ func (r *FileHashRepository) Update(fh *db_models.FileHash) error {
 	if fh.FilePath == existingFH.FilePath &&
 		fh.FileSize == existingFH.FileSize &&
 		fh.Hash == existingFH.Hash &&
+		fh.HashAlgorithm == existingFH.HashAlgorithm &&
+		fh.PartialHash == existingFH.PartialHash &&
+		fh.PartialWindow == existingFH.PartialWindow &&
 		fh.ModTime == existingFH.ModTime {
 		//do nothing
 		return nil
@@ -105,8 +126,8 @@ func (r *FileHashRepository) Update(fh *db_models.FileHash) error {
 	}
 
 	result, err = r.Db.Exec(`UPDATE file_hashes SET 
-		hash = ?, 	size = ?, 		modified_time = ? ,updated_at =?		WHERE 	id = ?`,
-		fh.Hash, fh.FileSize, fh.ModTime, time.Now().UTC().Format(time.RFC3339), existingFH.ID)
+		hash = ?, hash_algorithm = ?, partial_hash = ?, partial_window = ?, 	size = ?, 		modified_time = ? ,updated_at =?		WHERE 	id = ?`,
+		fh.Hash, fh.HashAlgorithm, fh.PartialHash, fh.PartialWindow, fh.FileSize, fh.ModTime, time.Now().UTC().Format(time.RFC3339), existingFH.ID)
 
 	if err != nil {
 		return err
@@ -125,19 +146,86 @@ func (r *FileHashRepository) Update(fh *db_models.FileHash) error {
 	return nil
 }
 
+// upsertQuery inserts a record or updates the one with the same path. updated_at and
+// seen_at are only set when the record changes; on insert the timestamp is its created_at.
+// A record saved by a scan is no longer an imported one.
+const upsertQuery = `
+	INSERT INTO file_hashes (path, hash, hash_algorithm, partial_hash, partial_window, size, modified_time, created_at, seen_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(path) DO UPDATE SET
+		hash = excluded.hash,
+		hash_algorithm = excluded.hash_algorithm,
+		partial_hash = excluded.partial_hash,
+		partial_window = excluded.partial_window,
+		size = excluded.size,
+		modified_time = excluded.modified_time,
+		imported = 0,
+		updated_at = excluded.created_at,
+		seen_at = excluded.seen_at
+	WHERE hash IS NOT excluded.hash
+		OR hash_algorithm IS NOT excluded.hash_algorithm
+		OR partial_hash IS NOT excluded.partial_hash
+		OR partial_window IS NOT excluded.partial_window
+		OR size IS NOT excluded.size
+		OR modified_time IS NOT excluded.modified_time
+		OR imported != 0`
+
+// Upsert creates the record of fh.FilePath or updates it if it changed.
 func (r *FileHashRepository) Upsert(fh *db_models.FileHash) error {
-	err := r.Update(fh)
+	return r.UpsertBatch([]*db_models.FileHash{fh})
+}
 
-	if err != nil && err.Error() == "not found" {
-		err := r.Create(fh)
-		if err != nil {
-			return err
+// UpsertBatch upserts every record in a single transaction: either all of them are
+// saved or none is.
+func (r *FileHashRepository) UpsertBatch(fhs []*db_models.FileHash) error {
+	if len(fhs) == 0 {
+		return nil
+	}
+
+	tx, err := r.Db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	stmt, err := tx.Prepare(upsertQuery)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, fh := range fhs {
+		if _, err := stmt.Exec(fh.FilePath, fh.Hash, fh.HashAlgorithm, fh.PartialHash, fh.PartialWindow, fh.FileSize, fh.ModTime, now, now); err != nil {
+			return fmt.Errorf("saving %s: %w", fh.FilePath, err)
 		}
 	}
-	if err != nil && err.Error() == "did not save" {
+	return tx.Commit()
+}
+
+// MarkSeen records that a scan walked the files at paths, in a single transaction.
+func (r *FileHashRepository) MarkSeen(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	tx, err := r.Db.Begin()
+	if err != nil {
 		return err
 	}
+	defer tx.Rollback() // no-op once committed
 
-	return nil
+	stmt, err := tx.Prepare("UPDATE file_hashes SET seen_at = ? WHERE path = ?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
 
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, path := range paths {
+		if _, err := stmt.Exec(now, path); err != nil {
+			return fmt.Errorf("marking %s: %w", path, err)
+		}
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"DuDe/internal/common"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNothingToPrune is returned for a PruneRequest that selects no entries at all.
+var ErrNothingToPrune = errors.New("nothing to prune: choose missing files, an age or a prefix")
+
+// CacheEntry is one file of the hash cache.
+type CacheEntry struct {
+	Path   string `json:"path"`
+	SeenAt string `json:"seenAt"` // when a scan last walked the file, RFC 3339
+}
+
+// CacheStats describes the hash cache in a directory.
+type CacheStats struct {
+	Path          string       `json:"path"`          // the database file
+	Entries       int64        `json:"entries"`       // cached files
+	SizeBytes     int64        `json:"sizeBytes"`     // the database file and its write-ahead log on disk
+	SchemaVersion int          `json:"schemaVersion"` // see LatestSchemaVersion
+	Oldest        []CacheEntry `json:"oldest"`        // the entries seen longest ago, oldest first
+}
+
+// PruneRequest selects the cache entries to remove. An entry is removed if any of the
+// set criteria selects it.
+type PruneRequest struct {
+	Missing       bool     `json:"missing"`       // files that no longer exist
+	OlderThanDays int      `json:"olderThanDays"` // files no scan walked for this many days; 0 disables it
+	Prefixes      []string `json:"prefixes"`      // files below any of these directories
+	Vacuum        bool     `json:"vacuum"`        // give the freed space back to the file system afterwards
+}
+
+// PruneReport tells how many entries each criterion of a PruneRequest removed and how
+// much the cache shrank. An entry selected by several criteria counts for the first.
+type PruneReport struct {
+	Missing     int64 `json:"missing"`
+	Old         int64 `json:"old"`
+	Prefixed    int64 `json:"prefixed"`
+	SizeBefore  int64 `json:"sizeBefore"`
+	SizeAfter   int64 `json:"sizeAfter"`
+	EntriesLeft int64 `json:"entriesLeft"`
+}
+
+// Stats describes the cache db opened from dir, with its oldest entries, at most oldest of them.
+func Stats(db *sql.DB, dir string, oldest int) (*CacheStats, error) {
+	stats := &CacheStats{Path: filepath.Join(dir, common.MemFilename), SizeBytes: sizeOnDisk(dir)}
+
+	var err error
+	if stats.SchemaVersion, err = SchemaVersion(db); err != nil {
+		return nil, err
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM file_hashes").Scan(&stats.Entries); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT path, COALESCE(seen_at, '') FROM file_hashes ORDER BY seen_at, path LIMIT ?", oldest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry CacheEntry
+		if err := rows.Scan(&entry.Path, &entry.SeenAt); err != nil {
+			return nil, err
+		}
+		stats.Oldest = append(stats.Oldest, entry)
+	}
+	return stats, rows.Err()
+}
+
+// Prune removes the entries req selects from the cache db opened from dir.
+func Prune(ctx context.Context, db *sql.DB, dir string, req PruneRequest) (*PruneReport, error) {
+	if !req.Missing && req.OlderThanDays <= 0 && len(req.Prefixes) == 0 {
+		return nil, ErrNothingToPrune
+	}
+	report := &PruneReport{SizeBefore: sizeOnDisk(dir)}
+
+	var err error
+	if req.Missing {
+		if report.Missing, err = pruneMissing(ctx, db); err != nil {
+			return nil, fmt.Errorf("pruning missing files: %w", err)
+		}
+	}
+	if req.OlderThanDays > 0 {
+		cutoff := time.Now().UTC().AddDate(0, 0, -req.OlderThanDays).Format(time.RFC3339)
+		if report.Old, err = exec(db, "DELETE FROM file_hashes WHERE seen_at IS NULL OR seen_at < ?", cutoff); err != nil {
+			return nil, fmt.Errorf("pruning old entries: %w", err)
+		}
+	}
+	for _, prefix := range req.Prefixes {
+		start, end := pathRange(prefix)
+		n, err := exec(db, "DELETE FROM file_hashes WHERE path >= ? AND path < ?", start, end)
+		if err != nil {
+			return nil, fmt.Errorf("pruning %s: %w", prefix, err)
+		}
+		report.Prefixed += n
+	}
+
+	if req.Vacuum {
+		if err := Vacuum(db); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM file_hashes").Scan(&report.EntriesLeft); err != nil {
+		return nil, err
+	}
+	report.SizeAfter = sizeOnDisk(dir)
+	return report, nil
+}
+
+// Vacuum rebuilds the cache db into the smallest file holding its entries and empties
+// its write-ahead log.
+func Vacuum(db *sql.DB) error {
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	return nil
+}
+
+// pruneMissing removes the entries of files that no longer exist. The paths are read
+// first, so no query is open while entries are deleted.
+func pruneMissing(ctx context.Context, db *sql.DB) (int64, error) {
+	rows, err := db.QueryContext(ctx, "SELECT path FROM file_hashes")
+	if err != nil {
+		return 0, err
+	}
+	var missing []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			missing = append(missing, path)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	stmt, err := tx.Prepare("DELETE FROM file_hashes WHERE path = ?")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, path := range missing {
+		if _, err := stmt.Exec(path); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(missing)), tx.Commit()
+}
+
+func exec(db *sql.DB, query string, args ...any) (int64, error) {
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// pathRange returns the bounds of the paths below dir: every such path is at least start
+// and less than end, and the index on path answers the range.
+func pathRange(dir string) (start, end string) {
+	start = strings.TrimSuffix(dir, string(filepath.Separator)) + string(filepath.Separator)
+	// The separator plus one is the first string after every path starting with start.
+	end = start[:len(start)-1] + string(rune(filepath.Separator+1))
+	return start, end
+}
+
+// sizeOnDisk returns the size of the cache files in dir: the database and its write-ahead log.
+func sizeOnDisk(dir string) int64 {
+	var size int64
+	for _, suffix := range []string{"", "-wal"} {
+		if info, err := os.Stat(filepath.Join(dir, common.MemFilename+suffix)); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSchemaTooNew is returned for a cache written by a newer DuDe, whose schema this
+// version cannot know.
+var ErrSchemaTooNew = errors.New("cache schema is newer than this version of DuDe supports")
+
+// migration upgrades the schema from version-1 to version. Caches from before
+// schema_version existed hold no record of what was applied, so every migration must
+// leave a schema that already has its change untouched.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// migrations are applied in order; append new ones, never change or reorder old ones.
+var migrations = []migration{
+	{version: 1, name: "create file_hashes", up: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS file_hashes (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				path TEXT UNIQUE,
+				hash TEXT,
+				size INTEGER,
+				modified_time TEXT,
+				updated_at TEXT,
+				created_at TEXT
+			)`)
+		return err
+	}},
+	// Databases created before the hash algorithm was recorded only hold MD5 hashes.
+	{version: 2, name: "record the hash algorithm", up: func(tx *sql.Tx) error {
+		return ensureColumn(tx, "file_hashes", "hash_algorithm", "TEXT NOT NULL DEFAULT 'md5'")
+	}},
+	{version: 3, name: "record the partial hash", up: func(tx *sql.Tx) error {
+		return ensureColumn(tx, "file_hashes", "partial_hash", "TEXT NOT NULL DEFAULT ''")
+	}},
+	// Records from before are taken as last seen when they were last written.
+	{version: 4, name: "record when a file was last seen", up: func(tx *sql.Tx) error {
+		if err := ensureColumn(tx, "file_hashes", "seen_at", "TEXT"); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE file_hashes SET seen_at = COALESCE(updated_at, created_at) WHERE seen_at IS NULL")
+		return err
+	}},
+	{version: 5, name: "mark imported entries", up: func(tx *sql.Tx) error {
+		return ensureColumn(tx, "file_hashes", "imported", "INTEGER NOT NULL DEFAULT 0")
+	}},
+	// The window of partial hashes from before is unknown, so they are never reused.
+	{version: 6, name: "record the partial hash window", up: func(tx *sql.Tx) error {
+		return ensureColumn(tx, "file_hashes", "partial_window", "INTEGER NOT NULL DEFAULT 0")
+	}},
+}
+
+// LatestSchemaVersion is the schema version Migrate upgrades a cache to.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrate brings the schema of db up to LatestSchemaVersion, applying each missing
+// migration in its own transaction together with its schema_version row.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TEXT NOT NULL
+		)`); err != nil {
+		return err
+	}
+
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > LatestSchemaVersion() {
+		return fmt.Errorf("%w: version %d, at most %d", ErrSchemaTooNew, current, LatestSchemaVersion())
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := apply(db, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// SchemaVersion returns the version of the last migration applied to db, 0 if none was.
+func SchemaVersion(db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRow("SELECT MAX(version) FROM schema_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+func apply(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+		m.version, m.name, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ensureColumn adds the column to the table if an older schema lacks it.
+func ensureColumn(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			ctype     string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
@@ -0,0 +1,215 @@
+package filters
+
+import (
+	"DuDe/internal/models"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPattern = errors.New("invalid glob pattern")
+	ErrInvalidSize    = errors.New("invalid size bound")
+	ErrInvalidTime    = errors.New("invalid time bound")
+)
+
+// timeLayouts are the accepted layouts of the modified-before/after bounds. Bounds without
+// a zone are local time.
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+// Filter decides during the walk which files are scanned. A nil Filter scans everything.
+type Filter struct {
+	include           []string
+	exclude           []string
+	extensions        map[string]bool
+	excludeExtensions map[string]bool
+	minSize           int64
+	maxSize           int64
+	after             time.Time
+	before            time.Time
+
+	respectIgnoreFiles bool
+	scanVCSDirs        bool
+	symlinkPolicy      string
+
+	ownDirs map[string]bool // cache and results directories inside a scan root
+}
+
+// New builds the filter configured in args, which the Resolver has normalised already.
+func New(args models.ExecutionParams) (*Filter, error) {
+	f := &Filter{
+		include:           args.Include,
+		exclude:           args.Exclude,
+		extensions:        toSet(NormalizeExtensions(args.Extensions)),
+		excludeExtensions: toSet(NormalizeExtensions(args.ExcludeExtensions)),
+		minSize:           args.MinSize,
+		maxSize:           args.MaxSize,
+
+		respectIgnoreFiles: args.RespectIgnoreFiles,
+		scanVCSDirs:        args.ScanVCSDirs,
+
+		ownDirs: ownDirs(args.Directories, args.CacheDir, args.ResultsDir),
+	}
+
+	for _, pattern := range slices.Concat(args.Include, args.Exclude) {
+		if err := ValidatePattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateSizes(args.MinSize, args.MaxSize); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateTimes(args.ModifiedAfter, args.ModifiedBefore); err != nil {
+		return nil, err
+	}
+	var err error
+	if f.symlinkPolicy, err = NormalizeSymlinkPolicy(args.SymlinkPolicy); err != nil {
+		return nil, err
+	}
+	f.after, _ = ParseTime(args.ModifiedAfter)
+	f.before, _ = ParseTime(args.ModifiedBefore)
+	return f, nil
+}
+
+// SkipDir reports whether the directory at rel, relative to the scan root and
+// slash-separated, matches an exclude pattern or is a version control directory.
+// Nothing below it is walked.
+func (f *Filter) SkipDir(rel string) bool {
+	if f == nil || rel == "." {
+		return false
+	}
+	if !f.scanVCSDirs && slices.Contains(VCSDirs, path.Base(rel)) {
+		return true
+	}
+	return matchAny(f.exclude, rel)
+}
+
+// MatchPath reports whether the file at rel, relative to the scan root and slash-separated,
+// passes the patterns and extension lists. It needs no stat, so it runs first.
+func (f *Filter) MatchPath(rel string) bool {
+	if f == nil {
+		return true
+	}
+	if matchAny(f.exclude, rel) {
+		return false
+	}
+	if len(f.include) > 0 && !matchAny(f.include, rel) {
+		return false
+	}
+
+	ext := extension(rel)
+	if f.excludeExtensions[ext] {
+		return false
+	}
+	return len(f.extensions) == 0 || f.extensions[ext]
+}
+
+// MatchInfo reports whether a file of the given size and modification time passes the
+// size and age bounds. MinSize and ModifiedAfter are inclusive, MaxSize too, ModifiedBefore is not.
+func (f *Filter) MatchInfo(size int64, modTime time.Time) bool {
+	if f == nil {
+		return true
+	}
+	if size < f.minSize || f.maxSize > 0 && size > f.maxSize {
+		return false
+	}
+	if !f.after.IsZero() && modTime.Before(f.after) {
+		return false
+	}
+	return f.before.IsZero() || modTime.Before(f.before)
+}
+
+// NormalizeExtensions lowercases extensions, adds the leading dot and drops duplicates and blanks,
+// so "JPG", ".jpg" and "jpg" are the same.
+func NormalizeExtensions(exts []string) []string {
+	var normalized []string
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" || ext == "." {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !slices.Contains(normalized, ext) {
+			normalized = append(normalized, ext)
+		}
+	}
+	return normalized
+}
+
+// ValidateSizes checks the size bounds in bytes; 0 means no bound.
+func ValidateSizes(minSize, maxSize int64) error {
+	if minSize < 0 || maxSize < 0 {
+		return fmt.Errorf("%w: sizes must not be negative", ErrInvalidSize)
+	}
+	if maxSize > 0 && minSize > maxSize {
+		return fmt.Errorf("%w: minimum %d is larger than maximum %d", ErrInvalidSize, minSize, maxSize)
+	}
+	return nil
+}
+
+// ParseTime parses a modified-before/after bound given as RFC 3339, "2006-01-02T15:04:05"
+// or "2006-01-02". An empty bound is the zero time.
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q (expected e.g. 2024-01-31 or %s)", ErrInvalidTime, value, time.RFC3339)
+}
+
+// ValidateTimes checks the modified-after/before bounds and that after is before before.
+func ValidateTimes(after, before string) error {
+	a, err := ParseTime(after)
+	if err != nil {
+		return err
+	}
+	b, err := ParseTime(before)
+	if err != nil {
+		return err
+	}
+	if !a.IsZero() && !b.IsZero() && !a.Before(b) {
+		return fmt.Errorf("%w: modified-after %s is not before modified-before %s", ErrInvalidTime, after, before)
+	}
+	return nil
+}
+
+// NormalizeTime parses value like ParseTime and returns it as RFC 3339, or "" for no bound.
+func NormalizeTime(value string) (string, error) {
+	t, err := ParseTime(value)
+	if err != nil || t.IsZero() {
+		return "", err
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func extension(rel string) string {
+	return strings.ToLower(path.Ext(rel))
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,63 @@
+package filters
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ValidatePattern reports whether pattern is a well-formed glob, see Match.
+func ValidatePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("%w: empty pattern", ErrInvalidPattern)
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("%w %q: %v", ErrInvalidPattern, pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match reports whether the slash-separated path rel matches pattern.
+// A pattern without a slash is matched against every element of rel, so "node_modules"
+// or "*.tmp" match at any depth. A pattern with a slash is matched against the whole of rel,
+// anchored at the scan root; "**" matches any number of directories, e.g. "photos/**/*.raw".
+func Match(pattern, rel string) bool {
+	pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+	if !strings.Contains(pattern, "/") {
+		for _, element := range strings.Split(rel, "/") {
+			if ok, _ := path.Match(pattern, element); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// "**" swallows zero or more segments.
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
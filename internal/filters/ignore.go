@@ -0,0 +1,155 @@
+package filters
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFiles are the gitignore-syntax files read in every directory when
+// ExecutionParams.RespectIgnoreFiles is set. Rules of later files take precedence.
+var IgnoreFiles = []string{".gitignore", ".dudeignore"}
+
+// VCSDirs are the version control directories skipped unless ExecutionParams.ScanVCSDirs is set.
+var VCSDirs = []string{".git", ".svn", ".hg"}
+
+// ignoreRule is one line of an ignore file.
+type ignoreRule struct {
+	segments []string // slash-separated pattern
+	negate   bool     // "!pattern" re-includes what earlier rules ignored
+	dirOnly  bool     // "pattern/" only matches directories
+	anchored bool     // a pattern with a slash matches relative to the ignore file's directory
+}
+
+// Ignores holds the ignore rules met while walking one scan root. Directories are
+// walked before their contents, so Load is called for a directory before Ignored is
+// asked about anything in it. A nil Ignores ignores nothing.
+type Ignores struct {
+	rules map[string][]ignoreRule // by directory, relative to the scan root
+}
+
+// NewIgnores returns an empty set of ignore rules for one walk, or nil if the filter
+// does not respect ignore files. An Ignores must not be shared between walks.
+func (f *Filter) NewIgnores() *Ignores {
+	if f == nil || !f.respectIgnoreFiles {
+		return nil
+	}
+	return &Ignores{rules: make(map[string][]ignoreRule)}
+}
+
+// Load reads the ignore files of the directory dir, found at rel below the scan root.
+// Missing ignore files are not an error.
+func (ig *Ignores) Load(dir, rel string) error {
+	if ig == nil {
+		return nil
+	}
+	var errs []error
+	for _, name := range IgnoreFiles {
+		f, err := os.Open(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rules, err := parseIgnoreFile(f)
+		f.Close()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		ig.rules[rel] = append(ig.rules[rel], rules...)
+	}
+	return errors.Join(errs...)
+}
+
+// Ignored reports whether the file or directory at rel, relative to the scan root and
+// slash-separated, is ignored. Ignore files deeper in the tree take precedence over those
+// above them, and within a file the last matching rule decides, as in git.
+func (ig *Ignores) Ignored(rel string, isDir bool) bool {
+	if ig == nil || rel == "." || len(ig.rules) == 0 {
+		return false
+	}
+
+	ignored := false
+	base := "."
+	for {
+		sub := rel
+		if base != "." {
+			sub = strings.TrimPrefix(rel, base+"/")
+		}
+		for _, rule := range ig.rules[base] {
+			if rule.match(sub, isDir) {
+				ignored = !rule.negate
+			}
+		}
+
+		next, _, found := strings.Cut(sub, "/")
+		if !found {
+			return ignored
+		}
+		if base == "." {
+			base = next
+		} else {
+			base = base + "/" + next
+		}
+	}
+}
+
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.anchored {
+		return matchSegments(r.segments, strings.Split(rel, "/"))
+	}
+	ok, _ := path.Match(r.segments[0], path.Base(rel))
+	return ok
+}
+
+// parseIgnoreFile reads gitignore syntax: blank lines and "#" comments are skipped,
+// "!" negates, a trailing "/" matches directories only and a leading or inner "/"
+// anchors the pattern. Lines that are not valid patterns are skipped, as git does.
+func parseIgnoreFile(r io.Reader) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" || ValidatePattern(line) != nil {
+		return ignoreRule{}, false
+	}
+	rule.segments = strings.Split(line, "/")
+	return rule, true
+}
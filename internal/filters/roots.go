@@ -0,0 +1,100 @@
+package filters
+
+import (
+	"DuDe/internal/common"
+	"path/filepath"
+	"strings"
+)
+
+// Canonical returns path absolute and cleaned, with every symlink in its existing
+// part resolved, so two spellings of the same directory compare equal.
+func Canonical(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	// The path does not exist (yet): resolve its parent and keep the rest.
+	parent := filepath.Dir(abs)
+	if parent == abs {
+		return abs
+	}
+	return filepath.Join(Canonical(parent), filepath.Base(abs))
+}
+
+// Within reports whether path is root or lies below it. Both must be clean and absolute.
+func Within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// CollapseRoots returns roots without those that repeat or lie inside another root,
+// which would otherwise be walked twice, and the roots it dropped. Roots are compared
+// by their Canonical paths; the first of several equal roots is kept.
+func CollapseRoots(roots []string) (kept, dropped []string) {
+	canonical := make([]string, len(roots))
+	for i, root := range roots {
+		canonical[i] = Canonical(root)
+	}
+	for i, root := range roots {
+		covered := false
+		for j := range roots {
+			if i == j || !Within(canonical[j], canonical[i]) {
+				continue
+			}
+			// Equal roots cover each other, so only the later one is dropped.
+			if canonical[i] != canonical[j] || j < i {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			dropped = append(dropped, root)
+		} else {
+			kept = append(kept, root)
+		}
+	}
+	return kept, dropped
+}
+
+// ownDirs returns those of the directories own that lie inside one of the scan roots dirs,
+// spelled as the walk spells them: below the root path it was given.
+func ownDirs(dirs []string, own ...string) map[string]bool {
+	found := make(map[string]bool)
+	for _, dir := range own {
+		if dir == "" {
+			continue
+		}
+		canonicalDir := Canonical(dir)
+		for _, root := range dirs {
+			canonicalRoot := Canonical(root)
+			if !Within(canonicalRoot, canonicalDir) {
+				continue
+			}
+			rel, _ := filepath.Rel(canonicalRoot, canonicalDir)
+			found[filepath.Join(root, rel)] = true
+		}
+	}
+	return found
+}
+
+// OwnDir reports whether the directory at path, as walked, is the cache or results
+// directory. Below a scan root it is skipped; as a scan root only the files DuDe
+// writes in it are, see OwnFile.
+func (f *Filter) OwnDir(path string) bool {
+	return f != nil && f.ownDirs[path]
+}
+
+// OwnFile reports whether the file at path, as walked, was written by DuDe: the cache
+// database, the action journal or a results file in the cache or results directory.
+func (f *Filter) OwnFile(path string) bool {
+	if f == nil || !f.ownDirs[filepath.Dir(path)] {
+		return false
+	}
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, common.MemFilename) || // with its -wal and -shm companions
+		name == common.JournalFilename ||
+		strings.HasPrefix(name, common.Results_file_name+"_")
+}
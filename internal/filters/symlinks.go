@@ -0,0 +1,45 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Symlink policies of the walk.
+const (
+	SymlinkSkip   = "skip"   // symlinks are neither walked nor hashed
+	SymlinkFollow = "follow" // symlinks are walked like their targets, each directory once
+	SymlinkReport = "report" // symlinks are not walked but listed as references in the results
+
+	DefaultSymlinkPolicy = SymlinkSkip
+)
+
+var ErrUnknownSymlinkPolicy = errors.New("unknown symlink policy")
+
+// SymlinkPolicies returns the names of all supported symlink policies.
+func SymlinkPolicies() []string {
+	return []string{SymlinkSkip, SymlinkFollow, SymlinkReport}
+}
+
+// NormalizeSymlinkPolicy lowercases policy and validates it. "" means DefaultSymlinkPolicy.
+func NormalizeSymlinkPolicy(policy string) (string, error) {
+	policy = strings.ToLower(strings.TrimSpace(policy))
+	if policy == "" {
+		return DefaultSymlinkPolicy, nil
+	}
+	for _, p := range SymlinkPolicies() {
+		if p == policy {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q (supported: %s)", ErrUnknownSymlinkPolicy, policy, strings.Join(SymlinkPolicies(), ", "))
+}
+
+// SymlinkPolicy returns how the walk treats symlinks.
+func (f *Filter) SymlinkPolicy() string {
+	if f == nil || f.symlinkPolicy == "" {
+		return DefaultSymlinkPolicy
+	}
+	return f.symlinkPolicy
+}
@@ -8,4 +8,5 @@ var (
 	ErrNoReadAccess     = errors.New("no read access")
 	ErrNoWriteAccess    = errors.New("no write access")
 	ErrNoDirectories    = errors.New("at least one directory must be provided")
+	ErrInsideReference  = errors.New("directory lies inside a reference directory")
 )
@@ -1,8 +1,13 @@
 package validation
 
 import (
+	"DuDe/internal/common/hashing"
+	"DuDe/internal/filters"
+	"DuDe/internal/keeprules"
 	"DuDe/internal/models"
+	"DuDe/internal/results"
 	"fmt"
+	"path/filepath"
 	"runtime"
 )
 
@@ -26,6 +31,16 @@ func (r Resolver) ResolveAndValidateArgs(args *models.ExecutionParams, exeDir st
 		}
 	}
 
+	// Absolute roots, each walked once: a root inside another one is already walked with it
+	args.Directories = resolveRoots(args.Directories)
+
+	// ReferenceDirs are walked too, but only files outside them are reported
+	if err := r.resolveReferences(args); err != nil {
+		return err
+	}
+	args.CacheDir = absolute(args.CacheDir)
+	args.ResultsDir = absolute(args.ResultsDir)
+
 	// CacheDir (writable, parent fallback)
 	if err := r.V.WritableDir(args.CacheDir); err != nil {
 		return fmt.Errorf("CacheDir: %w", err)
@@ -36,14 +51,149 @@ func (r Resolver) ResolveAndValidateArgs(args *models.ExecutionParams, exeDir st
 		return fmt.Errorf("ResultsDir: %w", err)
 	}
 
+	// HashAlgorithm (defaults to MD5 so existing caches stay valid)
+	args.HashAlgorithm = hashing.Normalize(args.HashAlgorithm)
+	if _, err := hashing.Get(args.HashAlgorithm); err != nil {
+		return fmt.Errorf("HashAlgorithm: %w", err)
+	}
+
+	// KeepRules (none means the original is the file with the smallest path)
+	if _, err := keeprules.Parse(args.KeepRules); err != nil {
+		return fmt.Errorf("KeepRules: %w", err)
+	}
+
+	// ResultFormats (defaults to CSV)
+	args.ResultFormats = results.Normalize(args.ResultFormats)
+	for _, format := range args.ResultFormats {
+		if _, err := results.Get(format); err != nil {
+			return fmt.Errorf("ResultFormats: %w", err)
+		}
+	}
+
+	// CSV options (comma and the latest layout by default)
+	delimiter, err := results.NormalizeDelimiter(args.CSVDelimiter)
+	if err != nil {
+		return fmt.Errorf("CSVDelimiter: %w", err)
+	}
+	args.CSVDelimiter = delimiter
+	layout, err := results.NormalizeCSVLayout(args.CSVLayout)
+	if err != nil {
+		return fmt.Errorf("CSVLayout: %w", err)
+	}
+	args.CSVLayout = layout
+
+	// Walk filters (none means every file is scanned)
+	if err := resolveFilters(args); err != nil {
+		return err
+	}
+
 	// resolve or validate the cpus
 	args.CPUs = resolveWorkers(&args.CPUs)
 
 	args.BufSize = resolveBufferSize(&args.BufSize)
 
+	args.PartialHashKiB = resolvePartialHashKiB(&args.PartialHashKiB)
+
+	return nil
+}
+
+func resolveFilters(args *models.ExecutionParams) error {
+	for _, pattern := range args.Include {
+		if err := filters.ValidatePattern(pattern); err != nil {
+			return fmt.Errorf("Include: %w", err)
+		}
+	}
+	for _, pattern := range args.Exclude {
+		if err := filters.ValidatePattern(pattern); err != nil {
+			return fmt.Errorf("Exclude: %w", err)
+		}
+	}
+	args.Extensions = filters.NormalizeExtensions(args.Extensions)
+	args.ExcludeExtensions = filters.NormalizeExtensions(args.ExcludeExtensions)
+
+	if err := filters.ValidateSizes(args.MinSize, args.MaxSize); err != nil {
+		return fmt.Errorf("MinSize/MaxSize: %w", err)
+	}
+
+	var err error
+	if args.ModifiedAfter, err = filters.NormalizeTime(args.ModifiedAfter); err != nil {
+		return fmt.Errorf("ModifiedAfter: %w", err)
+	}
+	if args.ModifiedBefore, err = filters.NormalizeTime(args.ModifiedBefore); err != nil {
+		return fmt.Errorf("ModifiedBefore: %w", err)
+	}
+	if err := filters.ValidateTimes(args.ModifiedAfter, args.ModifiedBefore); err != nil {
+		return fmt.Errorf("ModifiedAfter/ModifiedBefore: %w", err)
+	}
+	if args.SymlinkPolicy, err = filters.NormalizeSymlinkPolicy(args.SymlinkPolicy); err != nil {
+		return fmt.Errorf("SymlinkPolicy: %w", err)
+	}
+	return nil
+}
+
+// resolveRoots makes dirs absolute and drops those inside another one. The logger is not
+// initialised yet, so the dropped roots are only visible in the roots the walk logs.
+func resolveRoots(dirs []string) []string {
+	resolved := make([]string, len(dirs))
+	for i, dir := range dirs {
+		resolved[i] = absolute(dir)
+	}
+	roots, _ := filters.CollapseRoots(resolved)
+	return roots
+}
+
+// resolveReferences makes the reference directories absolute and adds them to the roots.
+// A directory that is also a reference is one; a directory inside a reference is an error,
+// as none of its files could be reported.
+func (r Resolver) resolveReferences(args *models.ExecutionParams) error {
+	if len(args.ReferenceDirs) == 0 {
+		return nil
+	}
+
+	refs := make([]string, 0, len(args.ReferenceDirs))
+	for i, dir := range args.ReferenceDirs {
+		if err := r.V.ReadableDir(dir); err != nil {
+			return fmt.Errorf("ReferenceDirs[%d] (%q): %w", i, dir, err)
+		}
+		refs = append(refs, absolute(dir))
+	}
+	refs, _ = filters.CollapseRoots(refs)
+
+	var targets []string
+	for i, dir := range args.Directories {
+		isReference := false
+		for _, ref := range refs {
+			if filters.Canonical(ref) == filters.Canonical(dir) {
+				isReference = true
+			} else if filters.Within(filters.Canonical(ref), filters.Canonical(dir)) {
+				return fmt.Errorf("Directories[%d] (%q): %w %s", i, dir, ErrInsideReference, ref)
+			}
+		}
+		if !isReference {
+			targets = append(targets, dir)
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("Directories: %w besides the reference directories", ErrNoDirectories)
+	}
+
+	args.ReferenceDirs = refs
+	args.Directories = resolveRoots(append(targets, refs...))
 	return nil
 }
 
+// absolute returns path absolute and cleaned, or unchanged if it is empty or has no absolute form.
+func absolute(path string) string {
+	if path == "" {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return abs
+}
+
 func resolveDir(value, fallback string) string {
 	if value == "" {
 		return fallback
@@ -80,3 +230,17 @@ func resolveBufferSize(value *int) int {
 	return *value
 
 }
+
+func resolvePartialHashKiB(value *int) int {
+	const defaultValue = 64
+	const maxValue = 16384
+
+	if value == nil || *value <= 0 {
+		return defaultValue
+	}
+	if *value > maxValue {
+		return maxValue
+	}
+
+	return *value
+}
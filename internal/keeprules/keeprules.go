@@ -0,0 +1,170 @@
+package keeprules
+
+import (
+	"DuDe/internal/models"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Supported rule kinds. Rules taking a value are written as "kind=value".
+const (
+	Oldest       = "oldest"        // keep the file modified first
+	Newest       = "newest"        // keep the file modified last
+	ShortestPath = "shortest-path" // keep the file with the shortest path
+	LongestPath  = "longest-path"  // keep the file with the longest path
+	PreferDir    = "prefer-dir"    // keep a file below the given directory, e.g. prefer-dir=/photos/master
+	Name         = "name"          // keep a file whose name matches the given glob, e.g. name=*.orig
+)
+
+var ErrInvalidRule = errors.New("invalid keep rule")
+
+// Rule is a single criterion for choosing the original of a duplicate group.
+type Rule struct {
+	Kind  string
+	Value string
+}
+
+// Rules are evaluated in order: the first rule that prefers one file over another decides.
+// Files no rule can tell apart are ordered by path, so the choice never depends on scan order.
+type Rules []Rule
+
+// Kinds returns the names of all supported rule kinds.
+func Kinds() []string {
+	return []string{Oldest, Newest, ShortestPath, LongestPath, PreferDir + "=DIR", Name + "=GLOB"}
+}
+
+// Parse parses rule specs such as "prefer-dir=/photos/master" or "oldest".
+func Parse(specs []string) (Rules, error) {
+	rules := make(Rules, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		rule, err := parseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseRule(spec string) (Rule, error) {
+	kind, value, hasValue := strings.Cut(spec, "=")
+	rule := Rule{Kind: strings.ToLower(strings.TrimSpace(kind)), Value: value}
+
+	switch rule.Kind {
+	case Oldest, Newest, ShortestPath, LongestPath:
+		if hasValue {
+			return Rule{}, fmt.Errorf("%w: %q takes no value", ErrInvalidRule, rule.Kind)
+		}
+	case PreferDir:
+		if value == "" {
+			return Rule{}, fmt.Errorf("%w: %q needs a directory", ErrInvalidRule, rule.Kind)
+		}
+		abs, err := filepath.Abs(value)
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+		rule.Value = abs
+	case Name:
+		if value == "" {
+			return Rule{}, fmt.Errorf("%w: %q needs a pattern", ErrInvalidRule, rule.Kind)
+		}
+		if _, err := filepath.Match(value, ""); err != nil {
+			return Rule{}, fmt.Errorf("%w: %q: %v", ErrInvalidRule, value, err)
+		}
+	default:
+		return Rule{}, fmt.Errorf("%w: unknown rule %q (supported: %s)", ErrInvalidRule, rule.Kind, strings.Join(Kinds(), ", "))
+	}
+	return rule, nil
+}
+
+// String returns the spec the rule was parsed from.
+func (r Rule) String() string {
+	if r.Value == "" {
+		return r.Kind
+	}
+	return r.Kind + "=" + r.Value
+}
+
+// compare returns a negative number if a should rather be kept than b,
+// a positive number if b should rather be kept and 0 if the rule cannot tell.
+func (r Rule) compare(a, b models.FileHash) int {
+	switch r.Kind {
+	case Oldest:
+		return compareModTime(a, b)
+	case Newest:
+		return -compareModTime(a, b)
+	case ShortestPath:
+		return len(a.FilePath) - len(b.FilePath)
+	case LongestPath:
+		return len(b.FilePath) - len(a.FilePath)
+	case PreferDir:
+		return compareBool(isBelow(a.FilePath, r.Value), isBelow(b.FilePath, r.Value))
+	case Name:
+		return compareBool(matches(r.Value, a.FileName), matches(r.Value, b.FileName))
+	}
+	return 0
+}
+
+// Sort orders files from the most to the least preferred original.
+func (rs Rules) Sort(files []models.FileHash) {
+	slices.SortStableFunc(files, func(a, b models.FileHash) int {
+		for _, rule := range rs {
+			if c := rule.compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.FilePath, b.FilePath)
+	})
+}
+
+// Group sorts files and returns the preferred original with all other files
+// as its DuplicatesFound.
+func (rs Rules) Group(files []models.FileHash) models.FileHash {
+	rs.Sort(files)
+	original := files[0]
+	original.DuplicatesFound = slices.Clone(files[1:])
+	return original
+}
+
+func compareModTime(a, b models.FileHash) int {
+	ta, errA := time.Parse(time.RFC3339, a.ModTime)
+	tb, errB := time.Parse(time.RFC3339, b.ModTime)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return ta.Compare(tb)
+}
+
+// compareBool prefers the file for which the rule holds.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func isBelow(path, dir string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, abs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func matches(pattern, name string) bool {
+	ok, _ := filepath.Match(pattern, name)
+	return ok
+}
@@ -3,11 +3,15 @@ package db_models
 import "database/sql"
 
 type FileHash struct {
-	ID       uint
-	FilePath string
-	Hash     string
-	FileSize int64
-	ModTime  string
+	ID            uint
+	FilePath      string
+	Hash          string
+	HashAlgorithm string
+	PartialHash   string
+	PartialWindow int64 // bytes hashed from each end of the file for PartialHash, 0 if unknown
+	FileSize      int64
+	ModTime       string
+	Imported      bool // imported from another machine and not yet checked against the file
 
 	// helpers
 	CreatedAt sql.NullString
@@ -11,21 +11,50 @@ type FileHash struct {
 	FileName        string
 	FilePath        string
 	Hash            string
+	HashAlgorithm   string
+	PartialHash     string // hash of the first/last PartialHashKiB of the file
+	PartialWindow   int64  // bytes hashed from each end of the file for PartialHash, 0 if unknown
 	ModTime         string
 	FileSize        int64
 	DuplicatesFound []FileHash
+	HardLinks       []string // other paths of the same file (hard links, or followed symlinks), collapsed into this one during the walk
+	ExternalLinks   int      // hard links to the same file outside the scan, which keep its data on disk whatever is done to its paths
+	Imported        bool     // a cached hash imported from another machine, not yet checked against the file
 }
 
 // TODO This should remain immutable!!not sure how to force this yet
 type ExecutionParams struct {
-	Directories  []string `json:"directories"`
-	UseCache     bool     `json:"useCache"`
-	CacheDir     string   `json:"cacheDir"`
-	ResultsDir   string   `json:"resultsDir"`
-	ParanoidMode bool     `json:"paranoidMode"`
-	CPUs         int      `json:"cpus"`
-	BufSize      int      `json:"bufSize"`
-	DebugMode    bool     `json:"debugMode"`
+	Directories    []string `json:"directories"`
+	UseCache       bool     `json:"useCache"`
+	CacheDir       string   `json:"cacheDir"`
+	ResultsDir     string   `json:"resultsDir"`
+	ParanoidMode   bool     `json:"paranoidMode"`
+	CPUs           int      `json:"cpus"`
+	BufSize        int      `json:"bufSize"`
+	DebugMode      bool     `json:"debugMode"`
+	HashAlgorithm  string   `json:"hashAlgorithm"`  // one of hashing.Algorithms(), "" means hashing.Default
+	PartialHashKiB int      `json:"partialHashKiB"` // KiB hashed from both ends of a file before full hashing
+	KeepRules      []string `json:"keepRules"`      // ordered keep rules choosing the original of each group, see keeprules.Parse
+	ResultFormats  []string `json:"resultFormats"`  // one file is written per format, see results.Formats; none means results.Default
+	CSVDelimiter   string   `json:"csvDelimiter"`   // single character separating CSV fields, "" means ","
+	CSVOmitBOM     bool     `json:"csvOmitBom"`     // do not start CSV files with the UTF-8 BOM Excel needs
+	CSVLayout      int      `json:"csvLayout"`      // CSV columns, see results.CSVLayoutPairs and results.CSVLayoutFiles; 0 means the latest
+
+	// Walk filters, see filters.Match for the pattern syntax. Excluded paths are never stat'ed or hashed.
+	Include            []string `json:"include"`            // glob patterns a file must match one of; none means every file
+	Exclude            []string `json:"exclude"`            // glob patterns of files and directories to skip
+	Extensions         []string `json:"extensions"`         // extensions a file must have one of, e.g. ".jpg"; none means every extension
+	ExcludeExtensions  []string `json:"excludeExtensions"`  // extensions to skip
+	MinSize            int64    `json:"minSize"`            // smallest file size in bytes scanned
+	MaxSize            int64    `json:"maxSize"`            // largest file size in bytes scanned, 0 means no limit
+	ModifiedAfter      string   `json:"modifiedAfter"`      // only files modified at or after this time (RFC 3339 or 2006-01-02)
+	ModifiedBefore     string   `json:"modifiedBefore"`     // only files modified before this time (RFC 3339 or 2006-01-02)
+	RespectIgnoreFiles bool     `json:"respectIgnoreFiles"` // skip what .gitignore and .dudeignore files in the scanned trees ignore
+	ScanVCSDirs        bool     `json:"scanVcsDirs"`        // also walk .git, .svn and .hg directories
+	ReportHardLinks    bool     `json:"reportHardLinks"`    // list files found under several paths because of hard links in the results
+	SymlinkPolicy      string   `json:"symlinkPolicy"`      // how the walk treats symlinks, see filters.SymlinkPolicies; "" means filters.DefaultSymlinkPolicy
+	CompareDirs        bool     `json:"compareDirs"`        // also report directories that are identical to or a subset of another one
+	ReferenceDirs      []string `json:"referenceDirs"`      // read-only directories: only files elsewhere with a copy in one are reported, and never the reference files
 }
 
 // DirectoryCount returns the number of directories configured for scanning.
@@ -0,0 +1,231 @@
+package processing
+
+import (
+	models "DuDe/internal/models"
+	"DuDe/internal/results"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// dirTree counts the files walked below every directory of the scan roots, so whole
+// directories can be compared once the duplicate groups are known. A nil dirTree counts nothing.
+type dirTree struct {
+	mu    sync.Mutex
+	files map[string]int // files walked below each directory, at every depth
+}
+
+func newDirTree() *dirTree {
+	return &dirTree{files: make(map[string]int)}
+}
+
+// add counts the file at path in every directory from its own up to root.
+func (t *dirTree) add(root, path string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		t.files[dir]++
+		if dir == root || filepath.Dir(dir) == dir {
+			return
+		}
+	}
+}
+
+// dirEntry is a file or subdirectory of a directory whose every file is a duplicate.
+type dirEntry struct {
+	name string
+	hash string // group key of a file, tree hash of a directory
+	dir  bool
+}
+
+// dirContents is what the duplicate groups hold below one walked directory.
+type dirContents struct {
+	files   int            // duplicate files, at every depth
+	size    int64          // their total size
+	hashes  map[string]int // number of files per group key, at every depth
+	entries []dirEntry     // files and subdirectories directly inside
+}
+
+// compare reports the sets of directories with identical trees and the directories whose
+// files all have a copy in a larger directory. Only directories all of whose files are in
+// groups can be either. Directories inside a reported one are not reported again.
+func (t *dirTree) compare(groups []models.FileHash) ([]results.DirectorySet, []results.DirectorySubset) {
+	if t == nil || len(groups) == 0 {
+		return nil, nil
+	}
+
+	contents := make(map[string]*dirContents)
+	paths := make(map[string][]string) // paths of the files per group key
+	get := func(dir string) *dirContents {
+		if contents[dir] == nil {
+			contents[dir] = &dirContents{hashes: make(map[string]int)}
+		}
+		return contents[dir]
+	}
+	for _, g := range groups {
+		// Groups of different sizes may share a hash, but never their content.
+		content := keyOf(g).String()
+		for _, fh := range append([]models.FileHash{g}, g.DuplicatesFound...) {
+			// The walk counted every hard link of a file in its own directory.
+			for _, path := range append([]string{fh.FilePath}, fh.HardLinks...) {
+				paths[content] = append(paths[content], path)
+				parent := get(filepath.Dir(path))
+				parent.entries = append(parent.entries, dirEntry{name: filepath.Base(path), hash: content})
+				for dir := filepath.Dir(path); t.files[dir] > 0; dir = filepath.Dir(dir) {
+					c := get(dir)
+					c.files++
+					c.size += fh.FileSize
+					c.hashes[content]++
+					if filepath.Dir(dir) == dir {
+						break
+					}
+				}
+			}
+		}
+	}
+	complete := func(dir string) bool {
+		return contents[dir] != nil && contents[dir].files == t.files[dir]
+	}
+
+	// Parents sort before their subdirectories.
+	dirs := make([]string, 0, len(contents))
+	for dir := range contents {
+		if complete(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	slices.Sort(dirs)
+
+	// Every subdirectory of a complete directory is complete, so its tree hash is known
+	// before its parent's once the directories are visited deepest first.
+	trees := make(map[string][]string) // directories per tree hash
+	treeOf := make(map[string]string)
+	for _, dir := range slices.Backward(dirs) {
+		treeOf[dir] = treeHash(contents[dir].entries)
+		trees[treeOf[dir]] = append(trees[treeOf[dir]], dir)
+		if parent := filepath.Dir(dir); parent != dir && complete(parent) {
+			contents[parent].entries = append(contents[parent].entries, dirEntry{name: filepath.Base(dir), hash: treeOf[dir], dir: true})
+		}
+	}
+
+	reported := make(map[string]bool)
+	var sets []results.DirectorySet
+	for _, dir := range dirs {
+		// Members were added deepest first, so the last one is the first met here. The set
+		// of the parents, if any, has been met before: its first member sorts before them all.
+		same := trees[treeOf[dir]]
+		if len(same) < 2 || same[len(same)-1] != dir {
+			continue
+		}
+		for _, d := range same {
+			reported[d] = true
+		}
+		if !slices.ContainsFunc(same, func(d string) bool { return !reported[filepath.Dir(d)] }) {
+			continue // the parents are a set already, which covers their trees
+		}
+		sets = append(sets, results.DirectorySet{
+			Paths: slices.Sorted(slices.Values(same)),
+			Files: contents[dir].files,
+			Size:  contents[dir].size,
+			Hash:  treeOf[dir],
+		})
+	}
+	// Largest first, like the groups of the HTML report.
+	slices.SortFunc(sets, func(a, b results.DirectorySet) int {
+		if a.Size != b.Size {
+			return cmp.Compare(b.Size, a.Size)
+		}
+		return strings.Compare(a.Paths[0], b.Paths[0])
+	})
+
+	var subsets []results.DirectorySubset
+	for _, dir := range dirs {
+		if reported[dir] || reported[filepath.Dir(dir)] {
+			reported[dir] = true
+			continue
+		}
+		for _, superset := range t.supersets(dir, contents, paths) {
+			subsets = append(subsets, results.DirectorySubset{Path: dir, Superset: superset, Files: contents[dir].files, Size: contents[dir].size})
+			reported[dir] = true
+		}
+	}
+	return sets, subsets
+}
+
+// supersets returns the deepest directories neither inside nor around dir holding a copy of
+// every file of dir and more files than it.
+func (t *dirTree) supersets(dir string, contents map[string]*dirContents, paths map[string][]string) []string {
+	own := contents[dir]
+
+	// A superset holds a copy of the rarest file of dir, so it is an ancestor of one.
+	var rarest string
+	for hash := range own.hashes {
+		if rarest == "" || len(paths[hash]) < len(paths[rarest]) || (len(paths[hash]) == len(paths[rarest]) && hash < rarest) {
+			rarest = hash
+		}
+	}
+	candidates := make(map[string]bool)
+	for _, path := range paths[rarest] {
+		if isWithin(dir, path) {
+			continue
+		}
+		for d := filepath.Dir(path); t.files[d] > 0 && !isWithin(d, dir); d = filepath.Dir(d) {
+			candidates[d] = true
+			if filepath.Dir(d) == d {
+				break
+			}
+		}
+	}
+
+	var found []string
+	for candidate := range candidates {
+		if t.files[candidate] > t.files[dir] && holds(contents[candidate].hashes, own.hashes) {
+			found = append(found, candidate)
+		}
+	}
+	// Keep the deepest: drop every candidate with another one below it.
+	var deepest []string
+	for _, d := range found {
+		if !slices.ContainsFunc(found, func(other string) bool { return other != d && isWithin(d, other) }) {
+			deepest = append(deepest, d)
+		}
+	}
+	slices.Sort(deepest)
+	return deepest
+}
+
+// holds reports whether the multiset of group keys all contains every one of part.
+func holds(all, part map[string]int) bool {
+	for hash, n := range part {
+		if all[hash] < n {
+			return false
+		}
+	}
+	return true
+}
+
+// isWithin reports whether path is dir or lies below it.
+func isWithin(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// treeHash hashes the names and hashes of the entries of a directory, in name order.
+func treeHash(entries []dirEntry) string {
+	slices.SortFunc(entries, func(a, b dirEntry) int { return strings.Compare(a.name, b.name) })
+	h := sha256.New()
+	for _, e := range entries {
+		kind := "f"
+		if e.dir {
+			kind = "d"
+		}
+		h.Write([]byte(kind + "\x00" + e.name + "\x00" + e.hash + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -1,26 +1,52 @@
 package processing
 
 import (
-	common "DuDe/internal/common"
 	log "DuDe/internal/common/logger"
+	"DuDe/internal/filters"
 	models "DuDe/internal/models"
+	"DuDe/internal/results"
 	visuals "DuDe/internal/visuals"
 	"context"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"io/fs"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"runtime"
-	"sync"
 )
 
-func WalkDir(ctx context.Context, path string, result *sync.Map, pt *visuals.ProgressCounter) {
+// walkState is what the walkers of one execution share.
+type walkState struct {
+	filter   *filters.Filter
+	links    *hardLinks   // paths of the same file, collapsed into one
+	symlinks *symlinkRefs // symlinks reported instead of followed
+	visited  *visitedDirs // directories walked when following symlinks
+	dirs     *dirTree     // files per directory, only when whole directories are compared
+}
+
+func newWalkState(filter *filters.Filter, compareDirs bool) *walkState {
+	state := &walkState{
+		filter:   filter,
+		links:    newHardLinks(),
+		symlinks: &symlinkRefs{},
+		visited:  &visitedDirs{dirs: make(map[string]bool)},
+	}
+	if compareDirs {
+		state.dirs = newDirTree()
+	}
+	return state
+}
+
+// WalkDir sends every file found below path that passes the filter of state to out. Hard links
+// to a file already walked are recorded in state instead of being sent, and so are symlinks
+// when they are reported. Sending blocks while the hashing stages are busy, so the walk never
+// runs far ahead of them.
+func WalkDir(ctx context.Context, path string, state *walkState, out chan<- models.FileHash, pt *visuals.ProgressCounter) {
 	defer func() {
 		pt.SenderFinished()
 	}()
@@ -28,7 +54,7 @@ func WalkDir(ctx context.Context, path string, result *sync.Map, pt *visuals.Pro
 	groupID := rand.Uint32()
 	log.InfoWithFuncName(fmt.Sprintf("Group %d started walking directory %s files", groupID, path))
 
-	err := filepath.WalkDir(path, storeFilePaths(ctx, result, pt))
+	err := filepath.WalkDir(path, storeFilePaths(ctx, path, state, out, pt))
 
 	if err != nil {
 		// Check if the error was due to user cancellation
@@ -42,8 +68,13 @@ func WalkDir(ctx context.Context, path string, result *sync.Map, pt *visuals.Pro
 	log.InfoWithFuncName(fmt.Sprintf("Group %d finished walking directory %s files", groupID, path))
 }
 
-func storeFilePaths(ctx context.Context, result *sync.Map, pt *visuals.ProgressCounter) func(path string, d fs.DirEntry, err error) error {
-	return func(path string, d fs.DirEntry, err error) error {
+func storeFilePaths(ctx context.Context, root string, state *walkState, out chan<- models.FileHash, pt *visuals.ProgressCounter) fs.WalkDirFunc {
+	filter := state.filter
+	follow := filter.SymlinkPolicy() == filters.SymlinkFollow
+	ignores := filter.NewIgnores()
+
+	var visit fs.WalkDirFunc
+	visit = func(path string, d fs.DirEntry, err error) error {
 
 		// --- 1. Cancellation Check ---
 		select {
@@ -69,110 +100,171 @@ func storeFilePaths(ctx context.Context, result *sync.Map, pt *visuals.ProgressC
 			// return err
 		}
 
-		if !d.IsDir() {
+		// Patterns and extensions are checked on the path alone, before anything is stat'ed.
+		rel := relativePath(root, path)
+		isLink := d.Type()&fs.ModeSymlink != 0
+		if isLink && !follow {
+			if filter.SymlinkPolicy() == filters.SymlinkReport && filter.MatchPath(rel) && !ignores.Ignored(rel, false) {
+				state.symlinks.add(path)
+			} else {
+				log.DebugWithFuncName(fmt.Sprintf("skipping symlink: %s", path))
+			}
+			return nil
+		}
 
-			result.Store(path, models.FileHash{FilePath: path})
-			pt.Channel <- 1
+		var info fs.FileInfo
+		if isLink {
+			// The symlink is walked like its target, under the path of the link.
+			if info, err = os.Stat(path); err != nil {
+				log.WarnWithFuncName(fmt.Sprintf("skipping broken symlink: %s reason: %s", path, err.Error()))
+				return nil
+			}
+			if info.IsDir() {
+				if filter.SkipDir(rel) || ignores.Ignored(rel, true) {
+					return nil
+				}
+				return walkSymlinkedDir(path, visit)
+			}
 		}
-		return nil
-	}
-}
 
-func SaveResultsAsCSV(data *sync.Map, fulldir string) error {
-	flattened_data := GetFlattened(data)
-	log.InfoWithFuncName(fmt.Sprintf("Number of duplicates found: %d", len(flattened_data)))
-	log.InfoWithFuncName(fmt.Sprintf("Creating results file in: %s", fulldir))
+		if d.IsDir() {
+			if filter.SkipDir(rel) || ignores.Ignored(rel, true) {
+				log.DebugWithFuncName(fmt.Sprintf("skipping excluded directory: %s", path))
+				return filepath.SkipDir
+			}
+			// The cache and results directories are never scanned, unless they are the scan root.
+			if path != root && filter.OwnDir(path) {
+				log.DebugWithFuncName(fmt.Sprintf("skipping DuDe directory: %s", path))
+				return filepath.SkipDir
+			}
+			// With symlinks followed every directory is walked once, which also stops loops.
+			if follow && !state.visited.add(path) {
+				log.DebugWithFuncName(fmt.Sprintf("skipping directory already walked: %s", path))
+				return filepath.SkipDir
+			}
+			// The ignore files of a directory apply to everything walked below it.
+			if err := ignores.Load(path, rel); err != nil {
+				log.WarnWithFuncName(fmt.Sprintf("could not read ignore files in %s: %v", path, err))
+			}
+			return nil
+		}
+		if !filter.MatchPath(rel) || ignores.Ignored(rel, false) || filter.OwnFile(path) {
+			return nil
+		}
 
-	if len(flattened_data) == 0 {
-		log.WarnWithFuncName("No results file produced, 0 duplicates found")
-		return nil
-	}
+		// Size and modification time are needed for the size-first staging.
+		if info == nil {
+			if info, err = d.Info(); err != nil {
+				log.WarnWithFuncName(fmt.Sprintf("skipping: %s reason: %s", path, err.Error()))
+				return nil
+			}
+		}
+		if !info.Mode().IsRegular() {
+			return nil // devices, sockets and pipes have no content to compare
+		}
+		if !filter.MatchInfo(info.Size(), info.ModTime()) {
+			return nil
+		}
 
-	file, err := createResultFile(fulldir)
-	if err != nil {
-		return err
+		fh := models.FileHash{
+			FileName: d.Name(),
+			FilePath: path,
+			FileSize: info.Size(),
+			ModTime:  info.ModTime().Format(time.RFC3339),
+		}
+		state.dirs.add(root, path)
+		// A followed symlink is another path of its target, like a hard link.
+		symlink := d.Type()&fs.ModeSymlink != 0
+		if id, nlink, ok := fileIdentity(info); ok && (nlink > 1 || follow) && !state.links.add(id, nlink, symlink, fh) {
+			log.DebugWithFuncName(fmt.Sprintf("collapsing another path of a file already walked: %s", path))
+			return nil
+		}
+		select {
+		case out <- fh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		select {
+		case pt.Channel <- 1:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	writer.Comma = GetDelimiterForOS()
+	return visit
+}
 
-	// Write the UTF-8 BOM bytes at the very beginning of the file to force stupid excel to recognise the encoding.
-	_, err = file.Write([]byte{0xEF, 0xBB, 0xBF})
+// walkSymlinkedDir walks the target of the symlinked directory link, reporting every
+// entry to visit under the path of the link.
+func walkSymlinkedDir(link string, visit fs.WalkDirFunc) error {
+	target, err := filepath.EvalSymlinks(link)
 	if err != nil {
-		return fmt.Errorf("failed to write UTF-8 BOM: %v", err)
+		log.WarnWithFuncName(fmt.Sprintf("skipping symlink: %s reason: %s", link, err.Error()))
+		return nil
 	}
+	return filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+		rel, relErr := filepath.Rel(target, path)
+		if relErr != nil {
+			return relErr
+		}
+		return visit(filepath.Join(link, rel), d, err)
+	})
+}
 
-	err = writer.Write(common.ResultsHeader)
+// relativePath returns path relative to root, slash-separated, as the filters expect it.
+func relativePath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
 	if err != nil {
-		return err
-	}
-
-	for _, entry := range flattened_data {
-		err = writer.Write([]string{
-			entry.Filename,
-			entry.FullPath,
-			entry.DuplicateFilename,
-			entry.DuplicateFullPath,
-		})
-
-		if err != nil {
-			return err
-		}
+		return filepath.ToSlash(path)
 	}
-
-	return nil
+	return filepath.ToSlash(rel)
 }
 
-func ResultsFileExist(path string) bool {
+// visitedDirs remembers the directories walked, by their path with all symlinks resolved.
+type visitedDirs struct {
+	mu   sync.Mutex
+	dirs map[string]bool
+}
 
-	entries, err := os.ReadDir(path)
+// add records the directory at path and reports whether it was not walked before.
+func (v *visitedDirs) add(path string) bool {
+	real, err := filepath.EvalSymlinks(path)
 	if err != nil {
-		fmt.Printf("Error reading directory: %v\n", err)
-		return false
+		return true
 	}
-
-	for _, entry := range entries {
-		// Skip directories to ensure we only process files
-		if entry.IsDir() {
-			continue
-		}
-
-		name := entry.Name()
-
-		// Check if file starts with "results" and ends with ".csv"
-		if strings.HasPrefix(name, "results") && strings.HasSuffix(name, ".csv") {
-			fmt.Printf("Found matching file: %s\n", name)
-			return true
-		}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.dirs[real] {
+		return false
 	}
-	return false
+	v.dirs[real] = true
+	return true
 }
 
-func GetDelimiterForOS() rune {
-	var delimiter rune
-	if runtime.GOOS == "windows" {
-		delimiter = ';'
-		log.InfoWithFuncName(fmt.Sprintf("Using (%c) delimiter for %s default.", delimiter, runtime.GOOS))
-	} else {
-		delimiter = ',' // Default for Linux, macOS, etc.
-		log.InfoWithFuncName(fmt.Sprintf("Using (%c) delimiter for %s default.", delimiter, runtime.GOOS))
-	}
-	return delimiter
+// symlinkRefs collects the symlinks met by walks that report them.
+type symlinkRefs struct {
+	mu    sync.Mutex
+	links []results.Symlink
 }
 
-func createResultFile(fulldir string) (*os.File, error) {
-
-	datetime := time.Now()
-	filename := fmt.Sprint(common.Results_file_name, datetime.Format("_2006_01_02_15_04_05"), ".", common.Results_file_extension)
-	filepath := filepath.Join(fulldir, filename)
-
-	file, err := os.Create(filepath)
+func (s *symlinkRefs) add(path string) {
+	target, err := os.Readlink(path)
 	if err != nil {
-		log.ErrorWithFuncName(fmt.Sprintf("Results file with name [%s] already exists", filename))
-		return nil, err
+		log.WarnWithFuncName(fmt.Sprintf("could not read symlink %s: %v", path, err))
+		return
 	}
-	return file, nil
+	_, statErr := os.Stat(path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links = append(s.links, results.Symlink{Path: path, Target: target, Broken: statErr != nil})
+}
+
+// sorted returns the symlinks collected, ordered by path.
+func (s *symlinkRefs) sorted() []results.Symlink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	links := slices.Clone(s.links)
+	slices.SortFunc(links, func(a, b results.Symlink) int { return strings.Compare(a.Path, b.Path) })
+	return links
 }
@@ -0,0 +1,116 @@
+package processing
+
+import (
+	models "DuDe/internal/models"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// fileID identifies a file independently of its paths.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// hardLinks collapses paths of the same file, hard links or followed symlinks, into one
+// logical file, so a file is never reported as its own duplicate. It is shared by all walkers.
+type hardLinks struct {
+	mu    sync.Mutex
+	files map[fileID]*linkedFile
+}
+
+// linkedFile is a file with several hard links, or walked through a symlink.
+type linkedFile struct {
+	paths  []models.FileHash // the first path is the one sent down the pipeline
+	nlink  uint64            // hard links to the file on disk
+	walked uint64            // hard links among paths, i.e. paths that are not followed symlinks
+}
+
+// externalLinks returns the number of hard links to the file that were not walked.
+func (f *linkedFile) externalLinks() int {
+	if f.nlink <= f.walked {
+		return 0
+	}
+	return int(f.nlink - f.walked)
+}
+
+func newHardLinks() *hardLinks {
+	return &hardLinks{files: make(map[fileID]*linkedFile)}
+}
+
+// add records fh, reached through a symlink if symlink is set, as a path of the file id
+// with nlink hard links and reports whether it is the first one walked.
+// Walking the same path twice, e.g. from nested roots, does not make it a hard link.
+func (h *hardLinks) add(id fileID, nlink uint64, symlink bool, fh models.FileHash) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f := h.files[id]
+	if f == nil {
+		f = &linkedFile{nlink: nlink}
+		h.files[id] = f
+	}
+	if slices.ContainsFunc(f.paths, func(p models.FileHash) bool { return p.FilePath == fh.FilePath }) {
+		return false
+	}
+	f.paths = append(f.paths, fh)
+	if !symlink {
+		f.walked++
+	}
+	return len(f.paths) == 1
+}
+
+// sets returns one FileHash per file walked under more than one path: the smallest path
+// with the others as HardLinks, ordered by path.
+func (h *hardLinks) sets() []models.FileHash {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var sets []models.FileHash
+	for _, f := range h.files {
+		if len(f.paths) > 1 {
+			sets = append(sets, collapse(f.paths))
+		}
+	}
+	slices.SortFunc(sets, func(a, b models.FileHash) int { return strings.Compare(a.FilePath, b.FilePath) })
+	return sets
+}
+
+// apply attaches the hard links, walked or not, to the hashed files in m, keyed by path,
+// and re-keys each of them by its smallest path, so the path reported does not depend on walk order.
+func (h *hardLinks) apply(m *sync.Map) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, f := range h.files {
+		external := f.externalLinks()
+		if len(f.paths) < 2 && external == 0 {
+			continue
+		}
+		v, ok := m.LoadAndDelete(f.paths[0].FilePath)
+		if !ok {
+			continue // never hashed, e.g. its size was unique
+		}
+		fh := v.(models.FileHash)
+		set := collapse(f.paths)
+		fh.FilePath, fh.FileName, fh.HardLinks = set.FilePath, set.FileName, set.HardLinks
+		fh.ExternalLinks = external
+		m.Store(fh.FilePath, fh)
+	}
+}
+
+func collapse(paths []models.FileHash) models.FileHash {
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = p.FilePath
+	}
+	slices.Sort(names)
+
+	fh := paths[0]
+	fh.FilePath = names[0]
+	fh.FileName = filepath.Base(names[0])
+	fh.HardLinks = names[1:]
+	return fh
+}
@@ -0,0 +1,17 @@
+//go:build linux
+
+package processing
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileIdentity returns the device and inode of a file and its number of hard links.
+func fileIdentity(info fs.FileInfo) (id fileID, nlink uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, 0, false
+	}
+	return fileID{dev: uint64(st.Dev), ino: st.Ino}, uint64(st.Nlink), true
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package processing
+
+import "io/fs"
+
+// fileIdentity is only implemented on Linux; elsewhere hard links are reported as duplicates.
+func fileIdentity(info fs.FileInfo) (id fileID, nlink uint64, ok bool) {
+	return fileID{}, 0, false
+}
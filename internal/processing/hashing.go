@@ -1,149 +1,44 @@
 package processing
 
 import (
-	com "DuDe/internal/common"
+	"DuDe/internal/common/ctxio"
+	"DuDe/internal/common/hashing"
 	log "DuDe/internal/common/logger"
+	"DuDe/internal/keeprules"
 	models "DuDe/internal/models"
 	visuals "DuDe/internal/visuals"
 	"bytes"
 	"context"
-	"crypto/md5"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"os"
-	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 )
 
-func CreateHashes(ctx context.Context, sourceFiles *sync.Map, maxWorkers int, pt *visuals.ProgressTracker, mm *MemoryManager, memory *map[string]models.FileHash, failedCount *int, errChan chan error) error {
-
-	time.Sleep(1000 * time.Millisecond)
-	numFilesToHash := com.LenSyncMap(sourceFiles)
-	if numFilesToHash == 0 {
-		mm.SenderFinished()
-		return nil // Nothing to hash
-	}
-
-	groupID := rand.Uint32()
-	log.InfoWithFuncName(fmt.Sprintf("Group %d started hashing %d files with %d workers", groupID, numFilesToHash, maxWorkers))
-	pt.AddTotal(int64(numFilesToHash))
-
-	var wg sync.WaitGroup
-
-	sem := make(chan struct{}, maxWorkers) // Define semaphore with buffer size
-
-	sourceFiles.Range(func(key, value interface{}) bool {
-
-		select {
-		case <-ctx.Done():
-			log.DebugWithFuncName(fmt.Sprintf("Group %d CreateHashes stopped spawning workers due to context cancellation.", groupID))
-			return false // Stop sourceFiles.Range loop
-		default:
-			// Continue spawning worker
-		}
-
-		wg.Add(1)
-		go func(path string, val models.FileHash) {
-			defer wg.Done()
-			var hash string
-			var err error
-
-			currentFilePath := key.(string)
-
-			// Acquire a slot
-			// --- 2. Check for Cancellation while waiting for Semaphore ---
-			select {
-			case <-ctx.Done():
-				// Context canceled while waiting for the semaphore
-				log.DebugWithFuncName(fmt.Sprintf("Worker skipped file. context canceled while waiting for semaphore. | filepath: %s", currentFilePath))
-				return // Exit the worker goroutine
-			case sem <- struct{}{}:
-				// Slot acquired, proceed
-			}
-			defer func() { <-sem }() // Release the slots
-			// --- 3. Check for Cancellation after acquiring slot (optional but good) ---
-			if ctx.Err() != nil {
-				log.DebugWithFuncName(fmt.Sprintf("Worker skipped file. context canceled immediately after semaphore acquisition. | filepath: %s", currentFilePath))
-				return
-			}
-
-			currentFileDiskStats, err := os.Stat(val.FilePath)
-			if err != nil {
-				errChan <- err
-				sourceFiles.Delete(val.FilePath)
-				pt.DecrementFromTotal() // remove for progress bar
-				return                  // stop this iteration
-			}
-
-			currentFileDiskSize := currentFileDiskStats.Size()
-			currentFileDiskModTime := currentFileDiskStats.ModTime().Format(time.RFC3339)
-
-			memoryOfFile, memoryExists := (*memory)[currentFilePath]
-
-			fileHasChangedOnDisk := memoryOfFile.FileSize != currentFileDiskSize || memoryOfFile.ModTime != currentFileDiskModTime
-
-			fileNeedsReHashing := !memoryExists || fileHasChangedOnDisk
-
-			if fileNeedsReHashing {
-				hash, err = calculateMD5Hash(ctx, val)
-				if errors.Is(err, context.Canceled) {
-					log.DebugWithFuncName(fmt.Sprintf("Hashing stopped due to context cancellation. | filepath: %s", currentFilePath))
-					return // Stop this iteration/worker
-				}
-				if err != nil {
-					sourceFiles.Delete(val.FilePath)
-					pt.DecrementFromTotal() // remove for progress bar
-					errChan <- err
-					return // stop this iteration
-				}
-
-				newMem := models.FileHash{
-					FileName: filepath.Base(path),
-					FilePath: path,
-					Hash:     hash,
-					FileSize: currentFileDiskSize,
-					ModTime:  currentFileDiskModTime,
-				}
-
-				sourceFiles.Store(path, newMem)
-				mm.Push(newMem)
-				// sendWithRetry(mm.Channel, newMem, 500*time.Millisecond, 5*time.Second, failedCount)
-
-			} else {
-				sourceFiles.Store(path, memoryOfFile)
-			}
-
-			// safeResend(mm.Channel, newMem, 500*time.Microsecond), something to never miss a new memory?
-
-			pt.Increment()
-		}(key.(string), value.(models.FileHash))
-		return true
-	})
-
-	wg.Wait()
-	mm.SenderFinished()
-	log.InfoWithFuncName(fmt.Sprintf("Group %d finished hashing %d files with %d workers", groupID, int64(numFilesToHash), maxWorkers))
-
-	close(sem)
-	// Check if the overall context was canceled or nil if not
-	return ctx.Err()
-}
-
 func EnsureDuplicates(ctx context.Context, input *sync.Map, pt *visuals.ProgressTracker, maxWorkers int) {
 	num := 0
 
-	// Check 1: Cancellation before starting any work (and before the first Range loop)
+	// Check 1: Cancellation before starting any work
 	if ctx.Err() != nil {
 		log.DebugWithFuncName("EnsureDuplicates skipped due to context cancellation.")
 		return
 	}
 
 	// This is usually quick, so no cancellation check needed here.
+	var groups []models.FileHash
 	input.Range(func(key, value any) bool {
-		num += len(value.(models.FileHash).DuplicatesFound)
+		item := value.(models.FileHash)
+		if len(item.DuplicatesFound) > 0 {
+			groups = append(groups, item)
+			num += len(item.DuplicatesFound)
+			for _, dup := range item.DuplicatesFound {
+				pt.AddPendingBytes(dup.FileSize)
+			}
+		}
 		return true
 	})
 
@@ -153,92 +48,93 @@ func EnsureDuplicates(ctx context.Context, input *sync.Map, pt *visuals.Progress
 
 	pt.AddTotal(int64(num))
 
+	jobs := make(chan models.FileHash)
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, maxWorkers)
 
-	input.Range(func(itemHash, item any) bool {
+	for range maxWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				ensureGroup(ctx, input, item, pt)
+			}
+		}()
+	}
 
+feed:
+	for _, item := range groups {
 		select {
 		case <-ctx.Done():
-
-			log.DebugWithFuncName("stopped spawning workers due to context cancellation.")
-
-			return false // Stop outer Range loop
-		default:
-			// Continue spawning worker
+			log.DebugWithFuncName("stopped feeding workers due to context cancellation.")
+			break feed
+		case jobs <- item:
 		}
+	}
+	close(jobs)
+	wg.Wait()
+}
 
-		wg.Add(1)
-		go func(itemHash string, item models.FileHash) {
-			defer wg.Done()
+// ensureGroup compares every duplicate of item byte-by-byte with item and drops the
+// ones that differ, deleting the group from input when no duplicate is left.
+func ensureGroup(ctx context.Context, input *sync.Map, item models.FileHash, pt *visuals.ProgressTracker) {
+	itemHash := item.Hash
 
-			// Check 3: Cancellation while waiting for semaphore
-			select {
-			case <-ctx.Done():
-				log.DebugWithFuncName(fmt.Sprintf("Worker for hash %s skipped: context canceled while waiting for semaphore.", itemHash))
-				return // Exit worker goroutine
-			case sem <- struct{}{}:
-				// Slot acquired, proceed
-			}
-			defer func() { <-sem }()
+	// Check 2: Cancellation before opening anything
+	if ctx.Err() != nil {
+		log.DebugWithFuncName(fmt.Sprintf("Worker for hash %s skipped: context canceled.", itemHash))
+		return
+	}
 
-			// Check 3b: Cancellation immediately after semaphore acquisition
-			if ctx.Err() != nil {
-				log.DebugWithFuncName(fmt.Sprintf("Worker for hash %s skipped: context canceled immediately after semaphore acquisition.", itemHash))
-				return
-			}
+	mainFile, err := os.Open(item.FilePath)
+	if err != nil {
+		log.WarnWithFuncName(fmt.Sprintf("skipping | Error opening file %s : %v.", item.FilePath, err))
+		for _, dup := range item.DuplicatesFound {
+			pt.AddBytes(dup.FileSize)
+			pt.Increment() // keep the tracker able to finish
+		}
+		return
+	}
+	defer mainFile.Close()
 
-			if len(item.DuplicatesFound) == 0 {
-				return
-			}
+	confirmed := make([]models.FileHash, 0, len(item.DuplicatesFound))
+	for _, dup := range item.DuplicatesFound {
 
-			mainFile, err := os.Open(item.FilePath)
+		// Check 3: Cancellation inside the comparison loop
+		if ctx.Err() != nil {
+			log.WarnWithFuncName(fmt.Sprintf("Worker for hash %s stopped mid-comparison loop due to cancellation.", itemHash))
+			return
+		}
 
-			if err != nil {
-				log.WarnWithFuncName(fmt.Sprintf("skipping | Error opening file %s : %v.", item.FilePath, err))
-				return
-			}
+		var bytesRead int64
+		eq, err := filesEqual(ctx, mainFile, dup.FilePath, func(n int64) {
+			bytesRead += n
+			pt.AddBytes(n)
+		})
+		if err != nil {
+			log.WarnWithFuncName(fmt.Sprintf("Error comparing files %s and %s: %v. Considering as equal.", item.FilePath, dup.FilePath, err))
+			eq = true
+		}
 
-			defer mainFile.Close()
-
-			for dupIndex := 0; dupIndex < len(item.DuplicatesFound); {
-
-				// Check 4: Cancellation inside the innermost loop
-				select {
-				case <-ctx.Done():
-					log.WarnWithFuncName(fmt.Sprintf("Worker for hash %s stopped mid-comparison loop due to cancellation.", itemHash))
-					return // Exit worker goroutine
-				default:
-					// Continue
-				}
-
-				dup := item.DuplicatesFound[dupIndex]
-
-				eq, err := filesEqual(ctx, mainFile, dup.FilePath)
-
-				if err != nil {
-					log.WarnWithFuncName(fmt.Sprintf("Error comparing files %s and %s: %v. Considering as equal.", item.FilePath, dup.FilePath, err))
-					eq = true
-				}
-
-				if !eq {
-					item.DuplicatesFound = append(item.DuplicatesFound[:dupIndex], item.DuplicatesFound[dupIndex+1:]...)
-					if len(item.DuplicatesFound) == 0 {
-						input.Delete(itemHash)
-					}
-				} else {
-					dupIndex++
-				}
-				// reset readers
-				_, _ = mainFile.Seek(0, io.SeekStart)
-				pt.Increment()
-			}
-		}(itemHash.(string), item.(models.FileHash))
-		return true
-	})
+		if eq {
+			confirmed = append(confirmed, dup)
+		}
+		// reset readers
+		_, _ = mainFile.Seek(0, io.SeekStart)
+		pt.AddBytes(dup.FileSize - bytesRead)
+		pt.Increment()
+	}
+
+	if len(confirmed) == 0 {
+		input.Delete(keyOf(item))
+		return
+	}
+	item.DuplicatesFound = confirmed
+	input.Store(keyOf(item), item)
 }
 
-func filesEqual(ctx context.Context, file1 *os.File, path2 string) (bool, error) {
+// filesEqual compares file1 with the file at path2 chunk by chunk, checking ctx between
+// chunks. onRead, when set, receives the number of bytes read from file1.
+func filesEqual(ctx context.Context, file1 *os.File, path2 string, onRead func(n int64)) (bool, error) {
 
 	// Check 1: Cancellation before opening the second file
 	if ctx.Err() != nil {
@@ -251,24 +147,23 @@ func filesEqual(ctx context.Context, file1 *os.File, path2 string) (bool, error)
 	}
 	defer file2.Close()
 
-	const chunkSize = 4096
-	buf1 := make([]byte, chunkSize)
-	buf2 := make([]byte, chunkSize)
+	r1 := ctxio.NewReader(ctx, file1, onRead)
+	r2 := ctxio.NewReader(ctx, file2, nil)
+	buf1 := make([]byte, ctxio.ChunkSize)
+	buf2 := make([]byte, ctxio.ChunkSize)
+
+	isEOF := func(err error) bool { return err == io.EOF || err == io.ErrUnexpectedEOF }
 
 	for {
+		n1, err1 := io.ReadFull(r1, buf1)
+		n2, err2 := io.ReadFull(r2, buf2)
 
-		// Check 2: Cancellation before starting the reads
-		select {
-		case <-ctx.Done():
-			return false, context.Canceled // Return cancellation error
-		default:
-			// continue
+		// Check 2: the readers stop as soon as the context is cancelled
+		if ctx.Err() != nil {
+			return false, ctx.Err()
 		}
 
-		n1, err1 := file1.Read(buf1)
-		n2, err2 := file2.Read(buf2)
-
-		if err1 != nil && err1 != io.EOF || err2 != nil && err2 != io.EOF {
+		if err1 != nil && !isEOF(err1) || err2 != nil && !isEOF(err2) {
 			return false, fmt.Errorf("read error: %w", errors.Join(err1, err2))
 		}
 
@@ -276,7 +171,7 @@ func filesEqual(ctx context.Context, file1 *os.File, path2 string) (bool, error)
 			return false, nil
 		}
 
-		if err1 == io.EOF && err2 == io.EOF {
+		if isEOF(err1) && isEOF(err2) {
 			break
 		}
 	}
@@ -286,47 +181,44 @@ func filesEqual(ctx context.Context, file1 *os.File, path2 string) (bool, error)
 	return true, nil
 }
 
-func calculateMD5Hash(ctx context.Context, file models.FileHash) (string, error) {
-
-	if ctx.Err() != nil {
-		return "", ctx.Err()
+// calculateHash hashes the whole file with hasher, checking ctx between chunks.
+// onRead, when set, receives the number of bytes read.
+func calculateHash(ctx context.Context, file models.FileHash, hasher hashing.Hasher, onRead func(n int64)) (string, error) {
+	hash, err := hashing.HashFile(ctx, hasher, file.FilePath, onRead)
+	if errors.Is(err, os.ErrPermission) {
+		log.WarnWithFuncName(fmt.Sprintf("Skipping file: %s, reason: %s", file.FilePath, err.Error()))
 	}
+	// TODO: add blob suffix for uniquness
+	return hash, err
+}
 
-	hasherMD5 := md5.New()
-
-	f, err := os.Open(file.FilePath)
-	if err != nil {
-		if errors.Is(err, os.ErrPermission) {
-			log.WarnWithFuncName(fmt.Sprintf("Skipping file: %s, reason: %s", file.FilePath, err.Error()))
-			return "", err
-		}
-		return "", fmt.Errorf("failed to open file: %w", err)
-	}
+// groupKey identifies a duplicate group. Files of different sizes are never duplicates,
+// even if their hashes collide.
+type groupKey struct {
+	size int64
+	hash string
+}
 
-	defer func() {
-		err := f.Close()
-		if err != nil {
-			log.ErrorWithFuncName(err.Error())
-		}
-	}()
+func keyOf(fh models.FileHash) groupKey {
+	return groupKey{size: fh.FileSize, hash: fh.Hash}
+}
 
-	// 💡 Performance Note: For cancellation during long reads,
-	// you would need a custom Reader that checks ctx.Done() periodically.
-	// For now, we assume the open/close is the main blocking point.
-	io.Copy(hasherMD5, f)
-	// TODO: add blob suffix for uniquness
-	return fmt.Sprintf("%x", hasherMD5.Sum(nil)), nil
+func (k groupKey) String() string {
+	return strconv.FormatInt(k.size, 10) + ":" + k.hash
 }
 
-func FindDuplicatesInMap(ctx context.Context, fileHashes *sync.Map, tracker *visuals.ProgressTracker) {
+// FindDuplicatesInMap replaces the contents of fileHashes (keyed by path) with one entry
+// per duplicate group (keyed by size and hash) and returns the number of groups.
+// The original of each group is the file rules prefer most.
+func FindDuplicatesInMap(ctx context.Context, fileHashes *sync.Map, tracker *visuals.ProgressTracker, rules keeprules.Rules) int {
 	timer := time.Now()
-	initialCount := com.LenSyncMap(fileHashes)
+	initialCount := 0
 
 	groupID := rand.Uint32()
-	log.InfoWithFuncName(fmt.Sprintf("Group %d started for source folder with %d files", groupID, initialCount))
+	log.InfoWithFuncName(fmt.Sprintf("Group %d started grouping files by hash", groupID))
 
-	hashCounts := make(map[string]int)
-	hashPaths := make(map[string][]models.FileHash)
+	hashCounts := make(map[groupKey]int)
+	hashPaths := make(map[groupKey][]models.FileHash)
 
 	fileHashes.Range(func(_, value any) bool {
 
@@ -338,74 +230,46 @@ func FindDuplicatesInMap(ctx context.Context, fileHashes *sync.Map, tracker *vis
 			// Continue
 		}
 
-		hash := value.(models.FileHash).Hash
+		key := keyOf(value.(models.FileHash))
 
-		hashCounts[hash]++
-		hashPaths[hash] = append(hashPaths[hash], value.(models.FileHash))
+		initialCount++
+		hashCounts[key]++
+		hashPaths[key] = append(hashPaths[key], value.(models.FileHash))
 		return true
 	})
 	if ctx.Err() != nil {
-		return // Exit the function entirely
+		return 0 // Exit the function entirely
 	}
 
 	totalGroups := len(hashPaths)
 	tracker.AddTotal(int64(totalGroups))
 
 	fileHashes.Clear()
+	duplicateGroups := 0
 
-	for hash, files := range hashPaths {
+	for key, files := range hashPaths {
 
 		select {
 		case <-ctx.Done():
 			log.DebugWithFuncName(fmt.Sprintf("Group %d stopped processing hash groups due to context cancellation.", groupID))
-			return // Exit the function entirely
+			return 0 // Exit the function entirely
 		default:
 			// Continue
 		}
 
 		if len(files) == 1 {
-			delete(hashPaths, hash)
+			delete(hashPaths, key)
 			tracker.Increment()
 		} else {
-			file := files[0] // smallest name?
-			dups := []models.FileHash{}
-			for i := 1; i < len(files); i++ {
-				dups = append(dups, files[i])
-			}
-			file.DuplicatesFound = dups
-			fileHashes.Store(file.Hash, file)
+			file := rules.Group(files)
+			fileHashes.Store(keyOf(file), file)
+			duplicateGroups++
 			tracker.Increment()
 		}
 	}
 
 	log.InfoWithFuncName(fmt.Sprintf("Group %d finished and, took : %s .source folder with %d files", groupID, time.Since(timer), initialCount))
-}
-
-func GetFlattened(input *sync.Map) []models.ResultEntry {
-	result := make([]models.ResultEntry, 0)
-
-	separatorEntry := models.ResultEntry{
-		Filename:          com.ResultsFileSeperator,
-		FullPath:          com.ResultsFileSeperator,
-		DuplicateFilename: com.ResultsFileSeperator,
-		DuplicateFullPath: com.ResultsFileSeperator}
-
-	input.Range(func(key, value any) bool {
-		val := value.(models.FileHash)
-		for _, dup := range val.DuplicatesFound {
-			a := models.ResultEntry{
-				Filename:          val.FileName,
-				FullPath:          val.FilePath,
-				DuplicateFilename: dup.FileName,
-				DuplicateFullPath: dup.FilePath,
-			}
-			result = append(result, a)
-		}
-		result = append(result, separatorEntry)
-
-		return true
-	})
-	return result
+	return duplicateGroups
 }
 
 // FIXME : unreachable code
@@ -8,19 +8,26 @@ import (
 
 func MapToServiceDTO(db_fh *db_models.FileHash) models.FileHash {
 	return models.FileHash{
-		FileName: filepath.Base(db_fh.FilePath),
-		FilePath: db_fh.FilePath,
-		Hash:     db_fh.Hash,
-		ModTime:  db_fh.ModTime,
-		FileSize: db_fh.FileSize,
+		FileName:      filepath.Base(db_fh.FilePath),
+		FilePath:      db_fh.FilePath,
+		Hash:          db_fh.Hash,
+		HashAlgorithm: db_fh.HashAlgorithm,
+		PartialHash:   db_fh.PartialHash,
+		PartialWindow: db_fh.PartialWindow,
+		ModTime:       db_fh.ModTime,
+		FileSize:      db_fh.FileSize,
+		Imported:      db_fh.Imported,
 	}
 }
 
 func MapToDomainDTO(ser_fh models.FileHash) db_models.FileHash {
 	return db_models.FileHash{
-		FilePath: ser_fh.FilePath,
-		Hash:     ser_fh.Hash,
-		ModTime:  ser_fh.ModTime,
-		FileSize: ser_fh.FileSize,
+		FilePath:      ser_fh.FilePath,
+		Hash:          ser_fh.Hash,
+		HashAlgorithm: ser_fh.HashAlgorithm,
+		PartialHash:   ser_fh.PartialHash,
+		PartialWindow: ser_fh.PartialWindow,
+		ModTime:       ser_fh.ModTime,
+		FileSize:      ser_fh.FileSize,
 	}
 }
@@ -1,39 +1,55 @@
 package processing
 
 import (
-	"DuDe/internal/common"
 	log "DuDe/internal/common/logger"
 	database "DuDe/internal/db"
 	models "DuDe/internal/models"
+	"DuDe/internal/models/db_models"
 	"database/sql"
+	"fmt"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// flushInterval bounds how long a cache write waits for its batch to fill up.
+const flushInterval = 500 * time.Millisecond
+
 type MemoryManager struct {
 	Channel     chan models.FileHash
 	repo        database.FileHashRepository
+	db          *sql.DB
+	batchSize   int
 	wg          sync.WaitGroup
 	senderWg    sync.WaitGroup
 	senderCount int32
 	isActive    bool
+
+	mu       sync.Mutex
+	warnings []string
+	seen     []string // paths of cached files walked by this scan
 }
 
+// NewMemoryManager opens the hash cache of args. A cache that cannot be opened is a
+// warning, and the scan runs without it.
 func NewMemoryManager(args *models.ExecutionParams, bufferSize, senderCount int) *MemoryManager {
-	var localdb *sql.DB
-	var err error
+	mm := &MemoryManager{
+		senderCount: int32(senderCount),
+		Channel:     make(chan models.FileHash, bufferSize),
+		batchSize:   max(bufferSize, 1),
+		isActive:    args.UseCache,
+	}
 	if args.UseCache {
-		localdb, err = database.InitializeDatabase(args.CacheDir)
+		localdb, err := database.InitializeDatabase(args.CacheDir)
 		if err != nil {
-			log.ErrorWithFuncName(err.Error())
+			mm.warn(fmt.Errorf("hash cache disabled: %w", err))
+			mm.isActive = false
 		}
+		mm.db = localdb
 	}
-
-	return &MemoryManager{
-		senderCount: int32(senderCount),
-		Channel:     make(chan models.FileHash, bufferSize),
-		repo:        *database.NewFileHashRepository(localdb),
-		isActive:    args.UseCache}
+	mm.repo = *database.NewFileHashRepository(mm.db)
+	return mm
 }
 
 func (mm *MemoryManager) Start() {
@@ -46,22 +62,53 @@ func (mm *MemoryManager) Start() {
 	go mm.updateMemory()
 }
 
-func (mm *MemoryManager) LoadMemory() map[string]models.FileHash {
+// LoadMemory returns the cached records of the files below roots, by path. Records of
+// files elsewhere are left in the cache, unread.
+func (mm *MemoryManager) LoadMemory(roots []string) map[string]models.FileHash {
 	result := make(map[string]models.FileHash)
 
 	if !mm.isActive { // return empty memory
-		return make(map[string]models.FileHash)
+		return result
 	}
 
-	records := common.Must(mm.repo.GetAll())
-
-	for _, val := range records {
-		result[val.FilePath] = MapToServiceDTO(val)
+	for _, root := range roots {
+		records, err := mm.repo.GetUnder(root)
+		if err != nil {
+			mm.warn(fmt.Errorf("hash cache of %s not read, its files are hashed: %w", root, err))
+			continue
+		}
+		for _, val := range records {
+			result[val.FilePath] = MapToServiceDTO(val)
+		}
 	}
 
 	return result
 }
 
+// Seen records that the scan walked the cached file at path, so pruning by age keeps it.
+func (mm *MemoryManager) Seen(path string) {
+	if !mm.isActive {
+		return
+	}
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.seen = append(mm.seen, path)
+}
+
+// Warnings returns the cache errors met so far. None of them stopped the scan.
+func (mm *MemoryManager) Warnings() []string {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return slices.Clone(mm.warnings)
+}
+
+func (mm *MemoryManager) warn(err error) {
+	log.WarnWithFuncName(err.Error())
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.warnings = append(mm.warnings, err.Error())
+}
+
 func (mm *MemoryManager) Wait() {
 
 	if !mm.isActive {
@@ -89,17 +136,58 @@ func (mm *MemoryManager) Push(fh models.FileHash) {
 	mm.Channel <- fh
 }
 
+// updateMemory saves the pushed records in transactions of up to batchSize records,
+// flushing a partial batch once flushInterval has passed.
 func (mm *MemoryManager) updateMemory() {
 	log.DebugWithFuncName("started")
 	defer mm.wg.Done()
+	defer mm.db.Close()
 
-	for fh := range mm.Channel {
-		db_fh := MapToDomainDTO(fh)
-		err := mm.repo.Upsert(&db_fh)
-		if err != nil {
-			log.FatalWithFuncName(err.Error())
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*db_models.FileHash, 0, mm.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// A failed batch only costs the next scan a rehash of its files.
+		if err := mm.repo.UpsertBatch(batch); err != nil {
+			mm.warn(fmt.Errorf("%d hashes not cached: %w", len(batch), err))
 		}
+		batch = batch[:0]
 	}
 
-	log.DebugWithFuncName("finished")
+	for {
+		select {
+		case fh, ok := <-mm.Channel:
+			if !ok {
+				flush()
+				mm.flushSeen()
+				log.DebugWithFuncName("finished")
+				return
+			}
+			db_fh := MapToDomainDTO(fh)
+			batch = append(batch, &db_fh)
+			if len(batch) >= mm.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// flushSeen marks the walked cached files as seen, batchSize paths per transaction.
+func (mm *MemoryManager) flushSeen() {
+	mm.mu.Lock()
+	seen := mm.seen
+	mm.seen = nil
+	mm.mu.Unlock()
+
+	for batch := range slices.Chunk(seen, mm.batchSize) {
+		if err := mm.repo.MarkSeen(batch); err != nil {
+			mm.warn(fmt.Errorf("%d cached files not marked as seen: %w", len(batch), err))
+		}
+	}
 }
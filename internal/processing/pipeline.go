@@ -0,0 +1,193 @@
+package processing
+
+import (
+	"DuDe/internal/common/hashing"
+	log "DuDe/internal/common/logger"
+	"DuDe/internal/filters"
+	"DuDe/internal/keeprules"
+	models "DuDe/internal/models"
+	"DuDe/internal/reporting"
+	"DuDe/internal/results"
+	"DuDe/internal/visuals"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExecutionResult holds everything a finished execution produced.
+type ExecutionResult struct {
+	Groups      []models.FileHash   // duplicate groups, each with DuplicatesFound populated
+	FilesFound  int                 // number of files discovered while walking
+	ResultFiles []string            // results files written, one per format
+	Summary     results.ScanSummary // statistics about the scan and its duplicates
+	HardLinks   []models.FileHash   // files walked under several paths, each with HardLinks populated
+	Symlinks    []results.Symlink   // symlinks found, only with the report symlink policy
+	Warnings    []string            // problems that did not stop the execution, e.g. an unusable hash cache
+
+	// Only when whole directories are compared.
+	DuplicateDirs []results.DirectorySet
+	SubsetDirs    []results.DirectorySubset
+}
+
+// HasDuplicates reports whether the execution found at least one duplicate group.
+func (r *ExecutionResult) HasDuplicates() bool {
+	return r != nil && len(r.Groups) > 0
+}
+
+// Execute runs the full processing pipeline (walk, hash, find, compare, save) for
+// already resolved and validated args. It has no dependency on the Wails runtime,
+// so it can be driven by the GUI as well as by the headless CLI.
+func Execute(ctx context.Context, args models.ExecutionParams, reporter reporting.Reporter) (*ExecutionResult, error) {
+	log.Initialize(args.DebugMode)
+
+	hasher, err := hashing.Get(args.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := keeprules.Parse(args.KeepRules)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := filters.New(args)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.Now()
+	log.LogModelArgs(args)
+
+	errChan := make(chan error, 100)
+	go func() {
+		for err := range errChan {
+			log.WarnWithFuncName(err.Error())
+		}
+	}()
+
+	var senderGroups int32 = int32(len(args.Directories))
+
+	mm := NewMemoryManager(&args, args.BufSize, 1)
+	mm.Start()
+
+	rt := visuals.NewProgressCounter(ctx, reporter, "Reading", int(senderGroups))
+	rt.Start()
+	// ^^^ slightly hacky and dump but works for now.
+
+	hashMemory := mm.LoadMemory(args.Directories)
+
+	// Hashing starts while the walk is still running; the tracker only reports
+	// once the walk is done so the two phases do not fight over the progress bar.
+	pt := visuals.NewProgressTracker(ctx, reporter, "Hashing")
+	stage := newHashStage(hasher, args.PartialHashKiB, hashMemory, mm, pt, errChan)
+
+	walked := make(chan models.FileHash, args.BufSize)
+	walk := newWalkState(filter, args.CompareDirs)
+	for _, dir := range args.Directories {
+		dir := dir // capture loop variable
+		go WalkDir(ctx, dir, walk, walked, rt)
+	}
+	walkFinished := make(chan struct{})
+	go func() {
+		rt.WaitForSenders()
+		close(walked)
+		pt.Start()
+		close(walkFinished)
+	}()
+
+	hashed := stage.run(ctx, walked, args.CPUs, args.BufSize)
+
+	// Collect while the stages are running, otherwise their backpressure would stall the walk.
+	var syncSourceDirFileMap sync.Map
+	for fh := range hashed {
+		syncSourceDirFileMap.Store(fh.FilePath, fh)
+	}
+
+	<-walkFinished
+	pt.Wait()
+	stage.wait() // nothing may write to the cache or errChan once they are closed
+	mm.SenderFinished()
+	mm.Wait()
+
+	close(errChan)
+
+	if err := ctx.Err(); err != nil {
+		log.ErrorWithFuncName(fmt.Sprintf("Error Hashing directory: %v", err))
+		return nil, err
+	}
+
+	// Every path of a hard-linked file is known only once the walk is done.
+	walk.links.apply(&syncSourceDirFileMap)
+
+	fileCount := int(stage.filesFound)
+	if fileCount == 0 {
+		reporter.LogProgress(ctx, "Error", 0)
+		reporter.LogDetailedStatus(ctx, "No files found in directory/directories! Check your paths again")
+		return &ExecutionResult{Warnings: mm.Warnings()}, nil
+	}
+
+	findTracker := visuals.NewProgressTracker(ctx, reporter, "Finding")
+	findTracker.Start()
+
+	length := FindDuplicatesInMap(ctx, &syncSourceDirFileMap, findTracker, rules)
+
+	findTracker.Wait()
+
+	log.InfoWithFuncName(fmt.Sprintf("found %v duplicates", length))
+	if length != 0 && args.ParanoidMode {
+		timer1 := time.Now()
+
+		compareTracker := visuals.NewProgressTracker(ctx, reporter, "Comparing")
+		compareTracker.Start()
+
+		EnsureDuplicates(ctx, &syncSourceDirFileMap, compareTracker, args.CPUs)
+
+		compareTracker.Wait()
+
+		log.InfoWithFuncName(fmt.Sprintf("Took: %s to look through bytes", time.Since(timer1)))
+	}
+
+	// Collect duplicate groups after the (optional) paranoid comparison so that
+	// groups rejected byte-by-byte are not reported.
+	var groups []models.FileHash
+	syncSourceDirFileMap.Range(func(_, v any) bool {
+		if fh, ok := v.(models.FileHash); ok && len(fh.DuplicatesFound) > 0 {
+			groups = append(groups, fh)
+		}
+		return true
+	})
+	// With reference directories only the copies of reference files are duplicates.
+	groups = newReferences(args.ReferenceDirs).restrict(groups, rules)
+	// Report groups in a stable order, independent of map iteration.
+	slices.SortFunc(groups, func(a, b models.FileHash) int { return strings.Compare(a.FilePath, b.FilePath) })
+
+	summary := results.Summarize(groups, args.Directories, stage.filesFound, stage.bytesFound)
+	log.InfoWithFuncName(fmt.Sprintf("%d duplicate files in %d groups, %d bytes reclaimable", summary.DuplicateFiles, summary.DuplicateGroups, summary.ReclaimableBytes))
+
+	hardLinkSets := walk.links.sets()
+	report := results.NewReport(groups, summary)
+	if args.ReportHardLinks {
+		report.HardLinks = results.NewHardLinkSets(hardLinkSets)
+	}
+	report.Symlinks = walk.symlinks.sorted()
+	report.DuplicateDirs, report.SubsetDirs = walk.dirs.compare(groups)
+
+	var resultFiles []string
+	if len(groups) > 0 || len(report.HardLinks) > 0 || len(report.Symlinks) > 0 {
+		resultFiles, err = results.Save(args.ResultsDir, report, args.ResultFormats, results.NewOptions(args))
+		if err != nil {
+			log.ErrorWithFuncName(fmt.Sprintf("Error saving result: %v", err))
+			return nil, err
+		}
+	} else {
+		log.InfoWithFuncName("No duplicates were found")
+	}
+
+	log.InfoWithFuncName(fmt.Sprintf("Took: %s for buffer size %d", time.Since(timer), args.BufSize))
+	reporter.LogProgress(ctx, "Done", 100)
+	reporter.FinishExecution(ctx)
+
+	return &ExecutionResult{Groups: groups, FilesFound: fileCount, ResultFiles: resultFiles, Summary: summary, HardLinks: hardLinkSets, Symlinks: report.Symlinks,
+		DuplicateDirs: report.DuplicateDirs, SubsetDirs: report.SubsetDirs, Warnings: mm.Warnings()}, nil
+}
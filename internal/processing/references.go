@@ -0,0 +1,68 @@
+package processing
+
+import (
+	"DuDe/internal/filters"
+	"DuDe/internal/keeprules"
+	models "DuDe/internal/models"
+	"path/filepath"
+)
+
+// references are the read-only reference directories of an execution. A nil references
+// has none, and every group is reported as found.
+type references struct {
+	dirs []string
+}
+
+func newReferences(dirs []string) *references {
+	if len(dirs) == 0 {
+		return nil
+	}
+	r := &references{}
+	for _, dir := range dirs {
+		r.dirs = append(r.dirs, filepath.Clean(dir))
+	}
+	return r
+}
+
+// contains reports whether the file at path lies inside a reference directory.
+func (r *references) contains(path string) bool {
+	if r == nil {
+		return false
+	}
+	for _, dir := range r.dirs {
+		if filters.Within(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// restrict keeps the groups that have files both inside and outside the reference
+// directories. The original of each is the reference file rules prefer, its duplicates
+// are the files outside; other reference copies are left out, so they are never acted on.
+func (r *references) restrict(groups []models.FileHash, rules keeprules.Rules) []models.FileHash {
+	if r == nil {
+		return groups
+	}
+
+	kept := groups[:0]
+	for _, g := range groups {
+		var refs, others []models.FileHash
+		for _, fh := range append([]models.FileHash{g}, g.DuplicatesFound...) {
+			fh.DuplicatesFound = nil
+			if r.contains(fh.FilePath) {
+				refs = append(refs, fh)
+			} else {
+				others = append(others, fh)
+			}
+		}
+		if len(refs) == 0 || len(others) == 0 {
+			continue
+		}
+		original := rules.Group(refs)
+		rules.Sort(others)
+		original.DuplicatesFound = others
+		kept = append(kept, original)
+	}
+	return kept
+}
@@ -1,23 +1,22 @@
 package processing
 
 import (
+	"DuDe/internal/actions"
 	"DuDe/internal/common"
 	"DuDe/internal/common/fs"
 	log "DuDe/internal/common/logger"
 	database "DuDe/internal/db"
 	"DuDe/internal/handlers/validation"
 	"DuDe/internal/reporting"
+	"DuDe/internal/results"
 
 	"errors"
+	"path/filepath"
 
 	"DuDe/internal/models"
-	"DuDe/internal/visuals"
 	"context"
 	"fmt"
 
-	"sync"
-	"time"
-
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -26,11 +25,17 @@ type FrontendApp struct {
 	wailsCtx   context.Context    // PERMANENT: Wails Context (Set once in WailsInit)
 	cancelFunc context.CancelFunc // TEMPORARY: Execution Context (Set in StartExecution, Cleared in defer)
 
-	platform    string
-	execCtx     context.Context
-	Args        models.ExecutionParams
-	reporter    reporting.Reporter
-	lastResults []models.FileHash // duplicate groups from the last completed execution
+	platform     string
+	execCtx      context.Context
+	Args         models.ExecutionParams
+	reporter     reporting.Reporter
+	lastResults  []models.FileHash         // duplicate groups from the last completed execution
+	lastSummary  *results.ScanSummary      // statistics of the last completed execution
+	lastLinks    []models.FileHash         // hard-link sets of the last completed execution
+	lastSymlinks []results.Symlink         // symlinks reported by the last completed execution
+	lastDirs     []results.DirectorySet    // directory sets found by the last completed execution
+	lastSubsets  []results.DirectorySubset // subset directories found by the last completed execution
+	lastWarnings []string                  // problems the last completed execution ran into without stopping
 }
 
 // NewApp creates a new App application struct
@@ -50,7 +55,7 @@ func (app *FrontendApp) CancelExecution() {
 }
 
 // FullReset stops any running execution, clears the cache database, and resets
-// all transient application state (Args, lastResults) back to zero values.
+// all transient application state (Args and all last* scan results) back to zero values.
 // The Wails context, execution context, cancel func, reporter, and platform are
 // intentionally left untouched.
 // A "fullReset" event is emitted so the frontend can reset its own state.
@@ -61,11 +66,7 @@ func (app *FrontendApp) FullReset() error {
 		app.cancelFunc()
 	}
 
-	// Resolve the cache directory — mirror the resolver fallback.
-	cacheDir := app.Args.CacheDir
-	if cacheDir == "" {
-		cacheDir = common.GetSafeResultsDir(app.platform)
-	}
+	cacheDir := app.cacheDir()
 
 	// Open the DB and truncate all cached hashes.
 	// A missing or un-initialised DB is not a fatal error for a full reset.
@@ -82,6 +83,12 @@ func (app *FrontendApp) FullReset() error {
 	// Reset transient state only.
 	app.Args = models.ExecutionParams{}
 	app.lastResults = nil
+	app.lastSummary = nil
+	app.lastLinks = nil
+	app.lastSymlinks = nil
+	app.lastDirs = nil
+	app.lastSubsets = nil
+	app.lastWarnings = nil
 
 	runtime.EventsEmit(app.wailsCtx, "fullReset", nil)
 	return nil
@@ -94,7 +101,7 @@ func (a *FrontendApp) Startup(ctx context.Context) {
 	a.platform = runtime.Environment(a.wailsCtx).Platform
 }
 
-// CheckIfResultsExist returns true if the results JSON file is found on disk
+// CheckIfResultsExist returns true if a results file of any format is found on disk
 func (a *FrontendApp) CheckIfResultsExist() bool {
 	var resultsDir string
 
@@ -103,7 +110,7 @@ func (a *FrontendApp) CheckIfResultsExist() bool {
 	} else {
 		resultsDir = a.Args.ResultsDir
 	}
-	return ResultsFileExist(resultsDir)
+	return results.Exists(resultsDir)
 }
 
 // ShowResults opens the results file defined in the execution arguments using the default OS handler.
@@ -160,6 +167,251 @@ func (a *FrontendApp) GetResults() []models.FileHash {
 	return a.lastResults
 }
 
+// GetSummary returns the statistics of the last completed execution, updated for any
+// action applied since. Returns nil if no execution has completed yet.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) GetSummary() *results.ScanSummary {
+	return a.lastSummary
+}
+
+// GetHardLinks returns the files the last completed execution found under several paths
+// because of hard links, each with HardLinks populated. They are never reported as duplicates.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) GetHardLinks() []models.FileHash {
+	return a.lastLinks
+}
+
+// GetSymlinks returns the symlinks the last completed execution found, with their targets,
+// when symlinks are reported rather than skipped or followed.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) GetSymlinks() []results.Symlink {
+	return a.lastSymlinks
+}
+
+// GetDuplicateDirs returns the sets of directories with identical trees the last completed
+// execution found, when whole directories are compared. Acting on duplicates clears them.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) GetDuplicateDirs() []results.DirectorySet {
+	return a.lastDirs
+}
+
+// GetSubsetDirs returns the directories whose every file has a copy in a larger directory,
+// when whole directories are compared. Acting on duplicates clears them.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) GetSubsetDirs() []results.DirectorySubset {
+	return a.lastSubsets
+}
+
+// GetWarnings returns the problems the last completed execution ran into without stopping,
+// such as a hash cache that could not be read or written.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) GetWarnings() []string {
+	return a.lastWarnings
+}
+
+// refreshSummary recomputes the summary of the current results, keeping the scanned totals.
+func (a *FrontendApp) refreshSummary() {
+	if a.lastSummary == nil {
+		return
+	}
+	summary := results.Summarize(a.lastResults, a.Args.Directories, a.lastSummary.FilesScanned, a.lastSummary.BytesScanned)
+	a.lastSummary = &summary
+}
+
+// ApplyAction deletes, quarantines, trashes or links every duplicate of the last
+// completed execution, keeping the original of each group. Files are re-verified right before
+// they are touched; acted-on duplicates are removed from the results.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) ApplyAction(req actions.Request) (*actions.Report, error) {
+	if a.cancelFunc != nil {
+		return nil, errors.New("an execution is still running")
+	}
+	if len(a.lastResults) == 0 {
+		return nil, actions.ErrNoResults
+	}
+
+	req.ReferenceDirs = a.Args.ReferenceDirs
+	req.JournalDir = a.cacheDir()
+	report, err := actions.Apply(a.wailsCtx, a.lastResults, req)
+	if report != nil {
+		a.lastResults = actions.Prune(a.lastResults, report)
+		a.refreshSummary()
+		if !report.DryRun && report.FilesActed > 0 {
+			// The directories no longer hold what they were compared on.
+			a.lastDirs, a.lastSubsets = nil, nil
+		}
+	}
+	if err != nil {
+		return report, err
+	}
+
+	log.InfoWithFuncName(fmt.Sprintf("%s: %d files acted on, %d failed, %d bytes reclaimed",
+		report.Action, report.FilesActed, report.FilesFailed, report.BytesFreed))
+	return report, nil
+}
+
+// RestoreFromJournal reverses a session (or a single group of it) recorded in the
+// undo journal next to the cache. An empty session restores the most recent one.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) RestoreFromJournal(req actions.RestoreRequest) (*actions.Report, error) {
+	if a.cancelFunc != nil {
+		return nil, errors.New("an execution is still running")
+	}
+
+	report, err := actions.RestoreFromJournal(a.wailsCtx, a.cacheDir(), req)
+	if err != nil {
+		return report, err
+	}
+
+	// Restored files are duplicates again; the cached results no longer reflect the disk.
+	a.lastResults = nil
+	a.lastSummary = nil
+	a.lastDirs, a.lastSubsets = nil, nil
+
+	log.InfoWithFuncName(fmt.Sprintf("restore of session %s: %d files restored, %d failed",
+		report.Session, report.FilesActed, report.FilesFailed))
+	return report, nil
+}
+
+// JournalSessions lists the sessions recorded in the undo journal, oldest first.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) JournalSessions() ([]actions.Session, error) {
+	return actions.Sessions(a.cacheDir())
+}
+
+// CacheStats describes the hash cache: its entries, size on disk and the entries no scan
+// walked for the longest time, at most oldest of them.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) CacheStats(oldest int) (*database.CacheStats, error) {
+	db, err := database.OpenCache(a.cacheDir())
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return database.Stats(db, a.cacheDir(), oldest)
+}
+
+// PruneCache removes the hash cache entries of missing files, of files no scan walked
+// for a number of days or of files below some directories, and optionally vacuums it.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) PruneCache(req database.PruneRequest) (*database.PruneReport, error) {
+	if a.cancelFunc != nil {
+		return nil, errors.New("an execution is still running")
+	}
+	// The cache stores absolute paths.
+	prefixes := make([]string, len(req.Prefixes))
+	for i, prefix := range req.Prefixes {
+		abs, err := filepath.Abs(prefix)
+		if err != nil {
+			return nil, err
+		}
+		prefixes[i] = abs
+	}
+	req.Prefixes = prefixes
+
+	db, err := database.OpenCache(a.cacheDir())
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return database.Prune(a.wailsCtx, db, a.cacheDir(), req)
+}
+
+// VacuumCache shrinks the hash cache file to its entries and returns its statistics afterwards.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) VacuumCache() (*database.CacheStats, error) {
+	if a.cancelFunc != nil {
+		return nil, errors.New("an execution is still running")
+	}
+	db, err := database.OpenCache(a.cacheDir())
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if err := database.Vacuum(db); err != nil {
+		return nil, err
+	}
+	return database.Stats(db, a.cacheDir(), 0)
+}
+
+// ExportCache writes the hash cache to the file at path so another machine can import it
+// and returns the number of entries written. With a root only the files below it are
+// exported, relative to it. It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) ExportCache(path, root string) (int64, error) {
+	db, err := database.OpenCache(a.cacheDir())
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	if root != "" {
+		if root, err = filepath.Abs(root); err != nil {
+			return 0, err
+		}
+	}
+	return database.ExportCacheFile(db, path, root)
+}
+
+// ImportCache adds the entries exported on another machine in the file at path to the hash
+// cache, below root if the export is relative. Scans only trust an imported entry once the
+// file's size, modification time and partial hash match it.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) ImportCache(path, root string) (*database.ImportReport, error) {
+	if a.cancelFunc != nil {
+		return nil, errors.New("an execution is still running")
+	}
+	db, err := database.InitializeDatabase(a.cacheDir())
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	if root != "" {
+		if root, err = filepath.Abs(root); err != nil {
+			return nil, err
+		}
+	}
+	return database.ImportCacheFile(db, path, root)
+}
+
+// cacheDir returns the cache directory, mirroring the resolver fallback.
+func (a *FrontendApp) cacheDir() string {
+	if a.Args.CacheDir == "" {
+		return common.GetSafeResultsDir(a.platform)
+	}
+	return a.Args.CacheDir
+}
+
+// ExportResults writes the current results, including the effect of any action applied
+// since the execution, into the results directory once per format and returns the paths written.
+// It is directly exposed to the JavaScript frontend.
+func (a *FrontendApp) ExportResults(formats []string) ([]string, error) {
+	if len(a.lastResults) == 0 {
+		return nil, actions.ErrNoResults
+	}
+	for _, format := range results.Normalize(formats) {
+		if _, err := results.Get(format); err != nil {
+			return nil, err
+		}
+	}
+
+	resultsDir := a.Args.ResultsDir
+	if resultsDir == "" {
+		resultsDir = common.GetSafeResultsDir(a.platform)
+	}
+	var summary results.ScanSummary
+	if a.lastSummary != nil {
+		summary = *a.lastSummary
+	}
+	report := results.NewReport(a.lastResults, summary)
+	if a.Args.ReportHardLinks {
+		report.HardLinks = results.NewHardLinkSets(a.lastLinks)
+	}
+	report.Symlinks = a.lastSymlinks
+	report.DuplicateDirs, report.SubsetDirs = a.lastDirs, a.lastSubsets
+	return results.Save(resultsDir, report, formats, results.NewOptions(a.Args))
+}
+
 // SelectFolder opens a native folder selection dialog and returns the selected path.
 // It is directly exposed to the JavaScript frontend.
 func (a *FrontendApp) SelectFolder() (string, error) {
@@ -195,6 +447,9 @@ func (a *FrontendApp) StartExecution(args models.ExecutionParams) error {
 	}
 
 	if err := resolver.ResolveAndValidateArgs(&args, safeDir); err != nil {
+		// Nothing runs; do not leave the app looking busy.
+		a.cancelFunc()
+		a.cancelFunc = nil
 		// Log the failure to the frontend
 		a.reporter.LogDetailedStatus(a.wailsCtx, fmt.Sprintf("Argument Validation Failed: %v", err))
 		// Throw an error back to the frontend to stop execution
@@ -211,8 +466,6 @@ func (a *FrontendApp) StartExecution(args models.ExecutionParams) error {
 }
 
 func startExecution(app *FrontendApp, reporter reporting.Reporter) error {
-	var err error
-
 	// Ensure cleanup of stored context when execution finishes normally
 	defer func() {
 		if app.cancelFunc != nil {
@@ -220,106 +473,20 @@ func startExecution(app *FrontendApp, reporter reporting.Reporter) error {
 			app.cancelFunc = nil
 		}
 	}()
-	log.Initialize(app.Args.DebugMode)
-
-	timer := time.Now()
-	log.LogModelArgs(app.Args)
-
-	errChan := make(chan error, 100)
-	go func() {
-		for err := range errChan {
-			log.WarnWithFuncName(err.Error())
-		}
-	}()
-
-	var senderGroups int32 = int32(len(app.Args.Directories))
-
-	failedCounter := 0
-	mm := NewMemoryManager(&app.Args, app.Args.BufSize, 1)
-	mm.Start()
 
-	rt := visuals.NewProgressCounter(app.execCtx, app.reporter, "Reading", int(senderGroups))
-	rt.Start()
-	// ^^^ slightly hacky and dump but works for now.
-
-	hashMemory := mm.LoadMemory()
-
-	var syncSourceDirFileMap sync.Map
-
-	for _, dir := range app.Args.Directories {
-		dir := dir // capture loop variable
-		go WalkDir(app.execCtx, dir, &syncSourceDirFileMap, rt)
-	}
-	rt.WaitForSenders()
-
-	fileCount := common.LenSyncMap(&syncSourceDirFileMap)
-	if fileCount == 0 {
-		app.reporter.LogProgress(app.execCtx, "Error", 0)
-		app.reporter.LogDetailedStatus(app.execCtx, "No files found in directory/directories! Check your paths again")
-		return nil
-	}
-
-	pt := visuals.NewProgressTracker(app.execCtx, reporter, "Hashing")
-	pt.Start()
-
-	err = CreateHashes(app.execCtx, &syncSourceDirFileMap, app.Args.CPUs, pt, mm, &hashMemory, &failedCounter, errChan)
+	result, err := Execute(app.execCtx, app.Args, reporter)
 	if err != nil {
-		log.ErrorWithFuncName(fmt.Sprintf("Error Hashing directory: %v", err))
 		return err
 	}
 
-	pt.Wait()
-	mm.Wait()
-
-	close(errChan)
-
-	findTracker := visuals.NewProgressTracker(app.execCtx, reporter, "Finding")
-	findTracker.Start()
-
-	FindDuplicatesInMap(app.execCtx, &syncSourceDirFileMap, findTracker)
-
-	findTracker.Wait()
-
-	// Collect duplicate groups and cache them for GetResults()
-	var groups []models.FileHash
-	syncSourceDirFileMap.Range(func(_, v any) bool {
-		if fh, ok := v.(models.FileHash); ok && len(fh.DuplicatesFound) > 0 {
-			groups = append(groups, fh)
-		}
-		return true
-	})
-	app.lastResults = groups
-
-	length := common.LenSyncMap(&syncSourceDirFileMap)
-
-	log.InfoWithFuncName(fmt.Sprintf("found %v duplicates", length))
-	if length != 0 {
-		timer1 := time.Now()
-
-		if app.Args.ParanoidMode {
-			compareTracker := visuals.NewProgressTracker(app.execCtx, reporter, "Comparing")
-			compareTracker.Start()
-
-			EnsureDuplicates(app.execCtx, &syncSourceDirFileMap, compareTracker, app.Args.CPUs)
-
-			compareTracker.Wait()
-		}
-
-		err = SaveResultsAsCSV(&syncSourceDirFileMap, app.Args.ResultsDir)
-		if err != nil {
-			log.FatalWithFuncName(fmt.Sprintf("Error saving result: %v", err))
-			return err
-		}
-
-		log.InfoWithFuncName(fmt.Sprintf("Took: %s to look through bytes", time.Since(timer1)))
-	} else {
-		log.InfoWithFuncName("No duplicates were found")
-	}
-
-	log.InfoWithFuncName(fmt.Sprintf("Took: %s for buffer size %d", time.Since(timer), app.Args.BufSize))
-	log.InfoWithFuncName(fmt.Sprintf("Failed %d times to send to memoryChan", failedCounter))
-	app.reporter.LogProgress(app.wailsCtx, "Done", 100)
-	app.reporter.FinishExecution(app.wailsCtx)
+	// Cache the duplicate groups and their summary for GetResults() and GetSummary()
+	app.lastResults = result.Groups
+	app.lastSummary = &result.Summary
+	app.lastLinks = result.HardLinks
+	app.lastSymlinks = result.Symlinks
+	app.lastDirs = result.DuplicateDirs
+	app.lastSubsets = result.SubsetDirs
+	app.lastWarnings = result.Warnings
 
 	return nil
 }
@@ -0,0 +1,380 @@
+package processing
+
+import (
+	"DuDe/internal/common"
+	"DuDe/internal/common/ctxio"
+	"DuDe/internal/common/hashing"
+	log "DuDe/internal/common/logger"
+	models "DuDe/internal/models"
+	visuals "DuDe/internal/visuals"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// hashStage holds what the partial and full hashing workers share.
+// Files flow through the stages as:
+//
+//	walkers → groupBySize → N partial hashers → groupByPartialHash → N full hashers → collector
+//
+// Every channel is bounded, so a slow stage applies backpressure to the walkers.
+type hashStage struct {
+	hasher  hashing.Hasher
+	window  int64                      // bytes hashed from each end of a file by the partial stage
+	memory  map[string]models.FileHash // cached hashes, read-only while the stage runs
+	mm      *MemoryManager
+	pt      *visuals.ProgressTracker
+	errChan chan error
+	wg      sync.WaitGroup // every goroutine of the stages, see wait
+
+	filesFound int64 // number of files received from the walkers
+	bytesFound int64 // total size of those files
+}
+
+func newHashStage(hasher hashing.Hasher, windowKiB int, memory map[string]models.FileHash, mm *MemoryManager, pt *visuals.ProgressTracker, errChan chan error) *hashStage {
+	return &hashStage{
+		hasher:  hasher,
+		window:  int64(windowKiB) * 1024,
+		memory:  memory,
+		mm:      mm,
+		pt:      pt,
+		errChan: errChan,
+	}
+}
+
+// run wires all stages together and returns the channel of fully hashed files.
+// The returned channel is closed once the full hashers have finished or ctx is cancelled;
+// earlier stages may still be running then, see wait.
+func (s *hashStage) run(ctx context.Context, walked <-chan models.FileHash, workers, bufSize int) <-chan models.FileHash {
+	sizeCandidates := make(chan models.FileHash, bufSize)
+	partiallyHashed := make(chan models.FileHash, bufSize)
+	fullCandidates := make(chan models.FileHash, bufSize)
+	hashed := make(chan models.FileHash, bufSize)
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		s.groupBySize(ctx, walked, sizeCandidates)
+	}()
+	runWorkers(ctx, &s.wg, workers, sizeCandidates, partiallyHashed, s.partialHash)
+	go func() {
+		defer s.wg.Done()
+		s.groupByPartialHash(ctx, partiallyHashed, fullCandidates)
+	}()
+	runWorkers(ctx, &s.wg, workers, fullCandidates, hashed, s.fullHash)
+
+	return hashed
+}
+
+// wait blocks until every goroutine of the stages has returned. Only then may the
+// cache channel and errChan be closed: after a cancel the partial stage can still be
+// caching or reporting errors when the full hashers are already done.
+func (s *hashStage) wait() {
+	s.wg.Wait()
+}
+
+// reportError hands err to errChan unless the execution was cancelled.
+func (s *hashStage) reportError(ctx context.Context, err error) {
+	select {
+	case s.errChan <- err:
+	case <-ctx.Done():
+	}
+}
+
+// groupBySize forwards a file as soon as another file of the same size has been seen.
+// Files whose size stays unique cannot have a duplicate and are never hashed.
+func (s *hashStage) groupBySize(ctx context.Context, in <-chan models.FileHash, out chan<- models.FileHash) {
+	defer close(out)
+
+	unique := forwardCollisions(ctx, in, out,
+		func(fh models.FileHash) int64 {
+			s.filesFound++
+			s.bytesFound += fh.FileSize
+			if _, cached := s.memory[fh.FilePath]; cached {
+				s.mm.Seen(fh.FilePath)
+			}
+			return fh.FileSize
+		},
+		func(models.FileHash) { s.pt.AddPending(1) },
+	)
+
+	log.InfoWithFuncName(fmt.Sprintf("Skipped %d files with a unique size", len(unique)))
+}
+
+// groupByPartialHash forwards a file as soon as another file with the same size and
+// partial hash has been seen. Once it returns no more work can enter any stage,
+// so it seals the progress total.
+func (s *hashStage) groupByPartialHash(ctx context.Context, in <-chan models.FileHash, out chan<- models.FileHash) {
+	defer close(out)
+	defer s.pt.SealTotal()
+
+	type partialKey struct {
+		size int64
+		hash string
+	}
+	unique := forwardCollisions(ctx, in, out,
+		func(fh models.FileHash) partialKey { return partialKey{fh.FileSize, fh.PartialHash} },
+		func(fh models.FileHash) {
+			s.pt.AddPending(1)
+			s.pt.AddPendingBytes(fh.FileSize)
+		},
+	)
+
+	// A cancelled run keeps nothing, not even the partial hashes.
+	if ctx.Err() != nil {
+		return
+	}
+	for _, fh := range unique {
+		s.rememberPartialHash(fh)
+	}
+	log.InfoWithFuncName(fmt.Sprintf("Skipped %d files with a unique partial hash", len(unique)))
+}
+
+// forwardCollisions reads in until it is closed and sends every item whose key was seen
+// at least twice to out: the first item of a key is held back until the second arrives.
+// It returns the items whose key stayed unique.
+func forwardCollisions[K comparable](ctx context.Context, in <-chan models.FileHash, out chan<- models.FileHash, key func(models.FileHash) K, onForward func(models.FileHash)) []models.FileHash {
+	pending := make(map[K]models.FileHash)
+	forwarded := make(map[K]bool)
+
+	send := func(fh models.FileHash) bool {
+		onForward(fh)
+		select {
+		case out <- fh:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for fh := range in {
+		k := key(fh)
+		switch first, isPending := pending[k]; {
+		case forwarded[k]:
+			if !send(fh) {
+				return nil
+			}
+		case isPending:
+			delete(pending, k)
+			forwarded[k] = true
+			if !send(first) || !send(fh) {
+				return nil
+			}
+		default:
+			pending[k] = fh
+		}
+	}
+
+	unique := make([]models.FileHash, 0, len(pending))
+	for _, fh := range pending {
+		unique = append(unique, fh)
+	}
+	return unique
+}
+
+// runWorkers starts n workers applying work to every file received from in and sending
+// the files work keeps to out. out is closed once all workers have returned; stageWG
+// tracks the workers as well.
+func runWorkers(ctx context.Context, stageWG *sync.WaitGroup, n int, in <-chan models.FileHash, out chan<- models.FileHash, work func(context.Context, models.FileHash) (models.FileHash, bool)) {
+	var wg sync.WaitGroup
+
+	for range n {
+		wg.Add(1)
+		stageWG.Add(1)
+		go func() {
+			defer stageWG.Done()
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case fh, ok := <-in:
+					if !ok {
+						return
+					}
+					result, keep := work(ctx, fh)
+					if !keep {
+						continue
+					}
+					select {
+					case out <- result:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+}
+
+// partialHash sets the hash of the first and last window bytes of the file,
+// reusing the cached partial hash when the cached entry still matches the file and was
+// taken with the same window. An imported partial hash is never reused: it is what
+// checks the imported entry.
+// A file of at most two windows is hashed completely, so its full hash is set as well.
+func (s *hashStage) partialHash(ctx context.Context, fh models.FileHash) (models.FileHash, bool) {
+	defer s.pt.Increment()
+
+	cached, exists := s.memory[fh.FilePath]
+	if exists && !cached.Imported && cached.PartialHash != "" && cached.PartialWindow == s.window &&
+		cached.HashAlgorithm == s.hasher.Algorithm() && cached.FileSize == fh.FileSize && common.SameModTime(cached.ModTime, fh.ModTime) {
+		fh.PartialHash = cached.PartialHash
+	} else {
+		partial, err := calculatePartialHash(ctx, fh, s.hasher, s.window)
+		if errors.Is(err, context.Canceled) {
+			return fh, false
+		}
+		if err != nil {
+			s.reportError(ctx, err)
+			return fh, false
+		}
+		fh.PartialHash = partial
+	}
+
+	fh.PartialWindow = s.window
+	if fh.FileSize <= 2*s.window {
+		fh.Hash = fh.PartialHash
+	}
+	return fh, true
+}
+
+// fullHash sets the hash of the whole file, reusing the cached hash when the file
+// has not changed on disk since it was cached with the same algorithm, or the hash of
+// the partial stage when that one read the whole file.
+func (s *hashStage) fullHash(ctx context.Context, val models.FileHash) (models.FileHash, bool) {
+	// Whatever happens to the file, account for all of its bytes once done with it.
+	var bytesRead int64
+	onRead := func(n int64) {
+		bytesRead += n
+		s.pt.AddBytes(n)
+	}
+	defer func() {
+		s.pt.AddBytes(val.FileSize - bytesRead)
+		s.pt.Increment()
+	}()
+
+	currentFilePath := val.FilePath
+
+	currentFileDiskStats, err := os.Stat(currentFilePath)
+	if err != nil {
+		s.reportError(ctx, err)
+		return val, false
+	}
+
+	currentFileDiskSize := currentFileDiskStats.Size()
+	currentFileDiskModTime := currentFileDiskStats.ModTime().Format(time.RFC3339)
+
+	memoryOfFile, memoryExists := s.memory[currentFilePath]
+
+	fileHasChangedOnDisk := memoryOfFile.FileSize != currentFileDiskSize || !common.SameModTime(memoryOfFile.ModTime, currentFileDiskModTime)
+
+	// A cached hash is only reusable if it was produced by the selected algorithm.
+	hashedWithOtherAlgorithm := memoryOfFile.HashAlgorithm != s.hasher.Algorithm()
+
+	// An imported hash is only trusted if the file still starts and ends as it did where it was hashed.
+	importedFromOtherFile := memoryOfFile.Imported &&
+		(memoryOfFile.PartialWindow != val.PartialWindow || memoryOfFile.PartialHash != val.PartialHash)
+
+	// Entries written by the partial hash stage carry no full hash.
+	fileNeedsReHashing := !memoryExists || fileHasChangedOnDisk || hashedWithOtherAlgorithm || importedFromOtherFile || memoryOfFile.Hash == ""
+
+	if !fileNeedsReHashing {
+		if memoryOfFile.PartialHash != val.PartialHash || memoryOfFile.PartialWindow != val.PartialWindow || memoryOfFile.Imported {
+			// Once checked, an imported entry is saved as this machine's own.
+			memoryOfFile.PartialHash = val.PartialHash
+			memoryOfFile.PartialWindow = val.PartialWindow
+			memoryOfFile.ModTime = currentFileDiskModTime
+			memoryOfFile.Imported = false
+			s.mm.Push(memoryOfFile)
+		}
+		return memoryOfFile, true
+	}
+
+	hash := val.Hash
+	if hash == "" || currentFileDiskSize != val.FileSize || !common.SameModTime(val.ModTime, currentFileDiskModTime) {
+		hash, err = calculateHash(ctx, val, s.hasher, onRead)
+		if errors.Is(err, context.Canceled) {
+			log.DebugWithFuncName(fmt.Sprintf("Hashing stopped due to context cancellation. | filepath: %s", currentFilePath))
+			return val, false
+		}
+		if err != nil {
+			s.reportError(ctx, err)
+			return val, false
+		}
+	}
+
+	newMem := models.FileHash{
+		FileName:      val.FileName,
+		FilePath:      currentFilePath,
+		Hash:          hash,
+		HashAlgorithm: s.hasher.Algorithm(),
+		PartialHash:   val.PartialHash,
+		PartialWindow: val.PartialWindow,
+		FileSize:      currentFileDiskSize,
+		ModTime:       currentFileDiskModTime,
+	}
+	s.mm.Push(newMem)
+
+	return newMem, true
+}
+
+// rememberPartialHash caches the partial hash of a file dropped by the partial stage,
+// so the next run can skip reading it, keeping a still valid full hash from an earlier run.
+func (s *hashStage) rememberPartialHash(fh models.FileHash) {
+	cached, exists := s.memory[fh.FilePath]
+	samePartial := cached.PartialHash == fh.PartialHash && cached.PartialWindow == fh.PartialWindow
+	cacheStillValid := exists && cached.HashAlgorithm == s.hasher.Algorithm() &&
+		cached.FileSize == fh.FileSize && common.SameModTime(cached.ModTime, fh.ModTime) &&
+		(!cached.Imported || samePartial)
+	if cacheStillValid && samePartial && !cached.Imported {
+		return
+	}
+	if cacheStillValid && fh.Hash == "" {
+		fh.Hash = cached.Hash
+	}
+	fh.HashAlgorithm = s.hasher.Algorithm()
+	s.mm.Push(fh)
+}
+
+// calculatePartialHash hashes the first and the last window bytes of the file.
+// Files no larger than two windows are hashed completely.
+func calculatePartialHash(ctx context.Context, file models.FileHash, hasher hashing.Hasher, window int64) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	f, err := os.Open(file.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := hasher.New()
+
+	if file.FileSize <= 2*window {
+		if _, err := ctxio.Copy(ctx, h, f, nil); err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+		return fmt.Sprintf("%x", h.Sum(nil)), nil
+	}
+
+	if _, err := io.CopyN(h, ctxio.NewReader(ctx, f, nil), window); err != nil {
+		return "", fmt.Errorf("failed to read head of file: %w", err)
+	}
+	if _, err := f.Seek(-window, io.SeekEnd); err != nil {
+		return "", fmt.Errorf("failed to seek to tail of file: %w", err)
+	}
+	if _, err := io.CopyN(h, ctxio.NewReader(ctx, f, nil), window); err != nil {
+		return "", fmt.Errorf("failed to read tail of file: %w", err)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,94 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ConsoleReporter implements the Reporter interface for the headless CLI.
+// Progress is rendered on a single, continuously rewritten line of Out.
+type ConsoleReporter struct {
+	Out io.Writer
+
+	mu      sync.Mutex
+	title   string
+	percent float64
+	current int64
+	total   int64
+
+	bytesCurrent int64
+	bytesTotal   int64
+}
+
+// NewConsoleReporter creates a ConsoleReporter writing to out.
+func NewConsoleReporter(out io.Writer) *ConsoleReporter {
+	return &ConsoleReporter{Out: out}
+}
+
+// LogDetailedStatus prints the message on its own line.
+func (c *ConsoleReporter) LogDetailedStatus(ctx context.Context, message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.Out, "\r\033[K%s\n", message)
+}
+
+// LogProgress updates the phase title and percentage of the progress line.
+func (c *ConsoleReporter) LogProgress(ctx context.Context, title string, percent float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if title != c.title {
+		// A new phase starts; finish the previous progress line.
+		if c.title != "" {
+			fmt.Fprintln(c.Out)
+		}
+		c.title = title
+		c.current, c.total = 0, 0
+		c.bytesCurrent, c.bytesTotal = 0, 0
+	}
+	c.percent = percent
+	c.render()
+}
+
+// LogFilesCount updates the file counters of the progress line.
+func (c *ConsoleReporter) LogFilesCount(ctx context.Context, current, total int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = current
+	c.total = total
+	c.render()
+}
+
+// LogBytesCount updates the byte counters of the progress line.
+func (c *ConsoleReporter) LogBytesCount(ctx context.Context, current, total int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bytesCurrent = current
+	c.bytesTotal = total
+	c.render()
+}
+
+// FinishExecution terminates the progress line.
+func (c *ConsoleReporter) FinishExecution(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.title != "" {
+		fmt.Fprintln(c.Out)
+	}
+	c.title = ""
+}
+
+// render must be called with c.mu held.
+func (c *ConsoleReporter) render() {
+	if c.total > 0 && c.bytesTotal > 0 {
+		fmt.Fprintf(c.Out, "\r\033[K%-10s %6.2f%% (%d/%d files, %d/%d MiB)", c.title, c.percent, c.current, c.total,
+			c.bytesCurrent>>20, c.bytesTotal>>20)
+		return
+	}
+	if c.total > 0 {
+		fmt.Fprintf(c.Out, "\r\033[K%-10s %6.2f%% (%d/%d)", c.title, c.percent, c.current, c.total)
+		return
+	}
+	fmt.Fprintf(c.Out, "\r\033[K%-10s %d files", c.title, c.current)
+}
@@ -8,6 +8,7 @@ type Reporter interface {
 	LogProgress(ctx context.Context, title string, percent float64)
 	LogDetailedStatus(ctx context.Context, message string)
 	LogFilesCount(ctx context.Context, current, total int64)
+	LogBytesCount(ctx context.Context, current, total int64)
 	FinishExecution(ctx context.Context)
 }
 
@@ -20,3 +21,8 @@ type FilesCountUpdate struct {
 	Current int64 `json:"current"`
 	Total   int64 `json:"total"`
 }
+
+type BytesCountUpdate struct {
+	Current int64 `json:"current"`
+	Total   int64 `json:"total"`
+}
@@ -28,6 +28,11 @@ func (l NoOpReporter) LogFilesCount(ctx context.Context, current, total int64) {
 	// l.T.Logf("E2E Files Count: %d / %d", current, total)
 }
 
+// LogBytesCount satisfies the interface contract but executes no Wails code.
+func (l NoOpReporter) LogBytesCount(ctx context.Context, current, total int64) {
+	// l.T.Logf("E2E Bytes Count: %d / %d", current, total)
+}
+
 // FinishExecution signals the endof execution to the frontend.
 func (l NoOpReporter) FinishExecution(ctx context.Context) {
 }
@@ -33,6 +33,15 @@ func (a *WailsReporter) LogFilesCount(ctx context.Context, current, total int64)
 	runtime.EventsEmit(ctx, "filesCount", update)
 }
 
+// LogBytesCount sends the bytes read so far and the bytes to read in total to the frontend.
+func (a *WailsReporter) LogBytesCount(ctx context.Context, current, total int64) {
+	update := BytesCountUpdate{
+		Current: current,
+		Total:   total,
+	}
+	runtime.EventsEmit(ctx, "bytesCount", update)
+}
+
 // FinishExecution signals the endof execution to the frontend.
 func (a *WailsReporter) FinishExecution(ctx context.Context) {
 	runtime.EventsEmit(ctx, "executionFinished")
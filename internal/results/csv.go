@@ -0,0 +1,164 @@
+package results
+
+import (
+	"DuDe/internal/common"
+	"DuDe/internal/models"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSV layouts. A layout never changes once released; new columns get a new layout.
+const (
+	CSVLayoutPairs = 1 // one row per original/duplicate pair, groups separated by separator rows
+	CSVLayoutFiles = 2 // one row per file with group ID, role, size, modification time and hash
+
+	DefaultCSVLayout    = CSVLayoutFiles
+	DefaultCSVDelimiter = ','
+)
+
+// csvWriter writes the groups as CSV in the layout selected by Options.
+type csvWriter struct{}
+
+func (csvWriter) Format() string    { return CSV }
+func (csvWriter) Extension() string { return common.Results_file_extension }
+
+func (csvWriter) Write(w io.Writer, report Report, opts Options) error {
+	writer, err := newCSVWriter(w, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.CSVLayout {
+	case CSVLayoutPairs:
+		err = writePairs(writer, report)
+	case CSVLayoutFiles, 0:
+		err = writeFiles(writer, report)
+	default:
+		err = fmt.Errorf("unknown CSV layout %d", opts.CSVLayout)
+	}
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteSummary writes the summary as rows of section, name, file count and bytes.
+func (csvWriter) WriteSummary(w io.Writer, report Report, opts Options) error {
+	writer, err := newCSVWriter(w, opts)
+	if err != nil {
+		return err
+	}
+
+	s := report.Summary
+	rows := [][]string{
+		common.SummaryHeader,
+		{"total", "scanned", itoa(s.FilesScanned), itoa(s.BytesScanned)},
+		{"total", "duplicate groups", itoa(int64(s.DuplicateGroups)), ""},
+		{"total", "reclaimable", itoa(int64(s.DuplicateFiles)), itoa(s.ReclaimableBytes)},
+	}
+	for _, g := range s.LargestGroups {
+		rows = append(rows, []string{"largest group", g.Original, itoa(int64(g.Files)), itoa(g.ReclaimableBytes)})
+	}
+	for _, b := range s.ByExtension {
+		rows = append(rows, []string{"extension", b.Key, itoa(int64(b.DuplicateFiles)), itoa(b.ReclaimableBytes)})
+	}
+	for _, b := range s.ByDirectory {
+		rows = append(rows, []string{"directory", b.Key, itoa(int64(b.DuplicateFiles)), itoa(b.ReclaimableBytes)})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return nil
+}
+
+// newCSVWriter writes the BOM unless omitted and returns a writer using the selected delimiter.
+func newCSVWriter(w io.Writer, opts Options) (*csv.Writer, error) {
+	if !opts.CSVOmitBOM {
+		// Write the UTF-8 BOM bytes at the very beginning of the file to force stupid excel to recognise the encoding.
+		if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return nil, fmt.Errorf("failed to write UTF-8 BOM: %v", err)
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = opts.CSVDelimiter
+	if writer.Comma == 0 {
+		writer.Comma = DefaultCSVDelimiter
+	}
+	return writer, nil
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+func writeFiles(writer *csv.Writer, report Report) error {
+	if err := writer.Write(common.ResultsHeaderV2); err != nil {
+		return err
+	}
+	for _, group := range report.Groups {
+		for _, f := range group.Files {
+			err := writer.Write([]string{
+				group.ID,
+				f.Role,
+				f.Name,
+				f.Path,
+				itoa(f.Size),
+				f.ModTime,
+				group.Hash,
+				group.HashAlgorithm,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writePairs(writer *csv.Writer, report Report) error {
+	if err := writer.Write(common.ResultsHeader); err != nil {
+		return err
+	}
+	for _, entry := range flatten(report) {
+		err := writer.Write([]string{
+			entry.Filename,
+			entry.FullPath,
+			entry.DuplicateFilename,
+			entry.DuplicateFullPath,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flatten(report Report) []models.ResultEntry {
+	result := make([]models.ResultEntry, 0)
+
+	separatorEntry := models.ResultEntry{
+		Filename:          common.ResultsFileSeperator,
+		FullPath:          common.ResultsFileSeperator,
+		DuplicateFilename: common.ResultsFileSeperator,
+		DuplicateFullPath: common.ResultsFileSeperator}
+
+	for _, group := range report.Groups {
+		original := group.Files[0]
+		for _, dup := range group.Files[1:] {
+			result = append(result, models.ResultEntry{
+				Filename:          original.Name,
+				FullPath:          original.Path,
+				DuplicateFilename: dup.Name,
+				DuplicateFullPath: dup.Path,
+			})
+		}
+		result = append(result, separatorEntry)
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package results
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"bytes": HumanBytes,
+}).Parse(reportTemplate))
+
+// htmlWriter writes a single offline HTML page: no scripts, styles or fonts are loaded from elsewhere.
+type htmlWriter struct{}
+
+func (htmlWriter) Format() string    { return HTML }
+func (htmlWriter) Extension() string { return "html" }
+
+// htmlGroup is a Group with the figures the page shows.
+type htmlGroup struct {
+	Group
+	Wasted int64
+}
+
+// dirSummary sums up the duplicates found in one directory.
+type dirSummary struct {
+	Dir        string
+	Duplicates int
+	Wasted     int64
+}
+
+type htmlPage struct {
+	Report
+	Groups      []htmlGroup
+	Directories []dirSummary
+	Files       int
+	Wasted      int64
+}
+
+func (htmlWriter) Write(w io.Writer, report Report, opts Options) error {
+	page := htmlPage{Report: report}
+
+	dirs := make(map[string]*dirSummary)
+	for _, g := range report.Groups {
+		wasted := g.Size * int64(len(g.Files)-1)
+		page.Groups = append(page.Groups, htmlGroup{Group: g, Wasted: wasted})
+		page.Files += len(g.Files)
+		page.Wasted += wasted
+
+		for _, f := range g.Files[1:] {
+			dir := filepath.Dir(f.Path)
+			if dirs[dir] == nil {
+				dirs[dir] = &dirSummary{Dir: dir}
+			}
+			dirs[dir].Duplicates++
+			dirs[dir].Wasted += f.Size
+		}
+	}
+
+	// Biggest savings first; ties in a stable order.
+	slices.SortStableFunc(page.Groups, func(a, b htmlGroup) int {
+		if a.Wasted != b.Wasted {
+			return compareDesc(a.Wasted, b.Wasted)
+		}
+		return strings.Compare(a.Files[0].Path, b.Files[0].Path)
+	})
+	for _, d := range dirs {
+		page.Directories = append(page.Directories, *d)
+	}
+	slices.SortFunc(page.Directories, func(a, b dirSummary) int {
+		if a.Wasted != b.Wasted {
+			return compareDesc(a.Wasted, b.Wasted)
+		}
+		return strings.Compare(a.Dir, b.Dir)
+	})
+
+	return htmlTemplate.Execute(w, page)
+}
+
+func compareDesc(a, b int64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
+
+// HumanBytes formats n with a binary unit, e.g. "1.5 MiB".
+func HumanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,97 @@
+package results
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonWriter writes the whole report as a single, indented JSON document.
+type jsonWriter struct{}
+
+func (jsonWriter) Format() string    { return JSON }
+func (jsonWriter) Extension() string { return "json" }
+
+func (jsonWriter) Write(w io.Writer, report Report, opts Options) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// ndjsonWriter writes one group per line, so consumers can stream the results, followed by
+// one line per reported hard-link set, symlink, directory set and subset directory and a
+// line with the summary.
+// The "type" of each line tells them apart.
+type ndjsonWriter struct{}
+
+func (ndjsonWriter) Format() string    { return NDJSON }
+func (ndjsonWriter) Extension() string { return "ndjson" }
+
+// NDJSON line types.
+const (
+	LineGroup    = "group"
+	LineHardLink = "hardlink"
+	LineSymlink  = "symlink"
+	LineDirs     = "directories"
+	LineSubset   = "subset"
+	LineSummary  = "summary"
+)
+
+type ndjsonGroup struct {
+	Type string `json:"type"`
+	Group
+}
+
+type ndjsonHardLink struct {
+	Type string `json:"type"`
+	HardLinkSet
+}
+
+type ndjsonSymlink struct {
+	Type string `json:"type"`
+	Symlink
+}
+
+type ndjsonDirs struct {
+	Type string `json:"type"`
+	DirectorySet
+}
+
+type ndjsonSubset struct {
+	Type string `json:"type"`
+	DirectorySubset
+}
+
+type ndjsonSummary struct {
+	Type string `json:"type"`
+	ScanSummary
+}
+
+func (ndjsonWriter) Write(w io.Writer, report Report, opts Options) error {
+	enc := json.NewEncoder(w)
+	for _, group := range report.Groups {
+		if err := enc.Encode(ndjsonGroup{Type: LineGroup, Group: group}); err != nil {
+			return err
+		}
+	}
+	for _, set := range report.HardLinks {
+		if err := enc.Encode(ndjsonHardLink{Type: LineHardLink, HardLinkSet: set}); err != nil {
+			return err
+		}
+	}
+	for _, link := range report.Symlinks {
+		if err := enc.Encode(ndjsonSymlink{Type: LineSymlink, Symlink: link}); err != nil {
+			return err
+		}
+	}
+	for _, set := range report.DuplicateDirs {
+		if err := enc.Encode(ndjsonDirs{Type: LineDirs, DirectorySet: set}); err != nil {
+			return err
+		}
+	}
+	for _, subset := range report.SubsetDirs {
+		if err := enc.Encode(ndjsonSubset{Type: LineSubset, DirectorySubset: subset}); err != nil {
+			return err
+		}
+	}
+	return enc.Encode(ndjsonSummary{Type: LineSummary, ScanSummary: report.Summary})
+}
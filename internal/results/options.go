@@ -0,0 +1,66 @@
+package results
+
+import (
+	"DuDe/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidDelimiter = errors.New("invalid CSV delimiter")
+	ErrUnknownCSVLayout = errors.New("unknown CSV layout")
+)
+
+// Options tune how the writers render a Report.
+type Options struct {
+	CSVDelimiter rune // 0 means DefaultCSVDelimiter
+	CSVOmitBOM   bool
+	CSVLayout    int // 0 means DefaultCSVLayout
+}
+
+// NewOptions returns the writer options selected in already resolved args.
+func NewOptions(args models.ExecutionParams) Options {
+	delimiter, _ := utf8.DecodeRuneInString(args.CSVDelimiter)
+	if delimiter == utf8.RuneError {
+		delimiter = 0
+	}
+	return Options{
+		CSVDelimiter: delimiter,
+		CSVOmitBOM:   args.CSVOmitBOM,
+		CSVLayout:    args.CSVLayout,
+	}
+}
+
+// NormalizeDelimiter turns a delimiter as typed by a user into a single character,
+// accepting "tab" and `\t` for a tab. "" means DefaultCSVDelimiter.
+func NormalizeDelimiter(delimiter string) (string, error) {
+	switch strings.ToLower(delimiter) {
+	case "":
+		return string(DefaultCSVDelimiter), nil
+	case "tab", `\t`:
+		return "\t", nil
+	case "comma":
+		return ",", nil
+	case "semicolon":
+		return ";", nil
+	}
+
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' || r == 0xFEFF {
+		return "", fmt.Errorf("%w: %q (use a single character other than a quote or line break)", ErrInvalidDelimiter, delimiter)
+	}
+	return delimiter, nil
+}
+
+// NormalizeCSVLayout applies the default for 0 and rejects unknown layouts.
+func NormalizeCSVLayout(layout int) (int, error) {
+	switch layout {
+	case 0:
+		return DefaultCSVLayout, nil
+	case CSVLayoutPairs, CSVLayoutFiles:
+		return layout, nil
+	}
+	return 0, fmt.Errorf("%w: %d (supported: %d, %d)", ErrUnknownCSVLayout, layout, CSVLayoutPairs, CSVLayoutFiles)
+}
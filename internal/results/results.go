@@ -0,0 +1,277 @@
+package results
+
+import (
+	"DuDe/internal/common"
+	log "DuDe/internal/common/logger"
+	"DuDe/internal/models"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Supported result formats.
+const (
+	CSV    = "csv"
+	JSON   = "json"
+	NDJSON = "ndjson"
+	HTML   = "html" // self-contained report for people rather than tools
+
+	// Default is the format written when none is selected.
+	Default = CSV
+)
+
+var ErrUnknownFormat = errors.New("unknown result format")
+
+// Writer renders a Report in a single format.
+type Writer interface {
+	// Format returns the name the format is selected by.
+	Format() string
+	// Extension returns the file extension, without the dot.
+	Extension() string
+	// Write renders report to w.
+	Write(w io.Writer, report Report, opts Options) error
+}
+
+var writers = map[string]Writer{
+	CSV:    csvWriter{},
+	JSON:   jsonWriter{},
+	NDJSON: ndjsonWriter{},
+	HTML:   htmlWriter{},
+}
+
+// Formats returns the names of all supported formats.
+func Formats() []string {
+	return []string{CSV, JSON, NDJSON, HTML}
+}
+
+// Normalize lower-cases, trims and de-duplicates the format names and applies the default for none.
+func Normalize(formats []string) []string {
+	var normalized []string
+	for _, format := range formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != "" && !slices.Contains(normalized, format) {
+			normalized = append(normalized, format)
+		}
+	}
+	if len(normalized) == 0 {
+		return []string{Default}
+	}
+	return normalized
+}
+
+// Get returns the Writer for the given format name.
+func Get(format string) (Writer, error) {
+	w, ok := writers[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (supported: %s)", ErrUnknownFormat, format, strings.Join(Formats(), ", "))
+	}
+	return w, nil
+}
+
+// Report is the format independent view of the duplicate groups of an execution.
+type Report struct {
+	Version     int           `json:"version"`
+	GeneratedAt string        `json:"generatedAt"`
+	Summary     ScanSummary   `json:"summary"`
+	Groups      []Group       `json:"groups"`
+	HardLinks   []HardLinkSet `json:"hardLinks,omitempty"` // only when hard-link sets are reported
+	Symlinks    []Symlink     `json:"symlinks,omitempty"`  // only with the report symlink policy
+
+	// Only when whole directories are compared.
+	DuplicateDirs []DirectorySet    `json:"duplicateDirs,omitempty"`
+	SubsetDirs    []DirectorySubset `json:"subsetDirs,omitempty"`
+}
+
+// Group is a set of files with identical content.
+type Group struct {
+	ID            string `json:"id"` // stable across runs for the same content
+	Hash          string `json:"hash"`
+	HashAlgorithm string `json:"hashAlgorithm"`
+	Size          int64  `json:"size"`
+	Files         []File `json:"files"` // the original first
+}
+
+// Roles of a file within its group.
+const (
+	RoleOriginal  = "original"
+	RoleDuplicate = "duplicate"
+)
+
+// File is a member of a Group.
+type File struct {
+	Name      string   `json:"name"`
+	Path      string   `json:"path"`
+	Size      int64    `json:"size"`
+	ModTime   string   `json:"modTime"`
+	Role      string   `json:"role"`
+	HardLinks []string `json:"hardLinks,omitempty"` // other paths of the same file, not counted as duplicates
+}
+
+// HardLinkSet is a file found under more than one path because of hard links.
+type HardLinkSet struct {
+	Path  string   `json:"path"`
+	Links []string `json:"links"`
+	Size  int64    `json:"size"`
+}
+
+// Symlink is a symbolic link found by a walk that reports symlinks instead of following them.
+type Symlink struct {
+	Path   string `json:"path"`
+	Target string `json:"target"`           // as stored in the link, possibly relative
+	Broken bool   `json:"broken,omitempty"` // the target does not exist
+}
+
+// DirectorySet is a set of directories with identical trees: the same names at every
+// depth, each holding the same content.
+type DirectorySet struct {
+	Paths []string `json:"paths"`
+	Files int      `json:"files"` // in each directory, at every depth
+	Size  int64    `json:"size"`  // of each directory
+	Hash  string   `json:"hash"`  // of the tree, from the names and content hashes of its entries
+}
+
+// DirectorySubset is a directory whose every file has a copy in the Superset directory,
+// which holds more files.
+type DirectorySubset struct {
+	Path     string `json:"path"`
+	Superset string `json:"superset"`
+	Files    int    `json:"files"` // in Path, at every depth
+	Size     int64  `json:"size"`  // of Path
+}
+
+// NewHardLinkSets converts hard-link sets, each the smallest path with the others as
+// HardLinks, for the HardLinks of a Report.
+func NewHardLinkSets(sets []models.FileHash) []HardLinkSet {
+	converted := make([]HardLinkSet, 0, len(sets))
+	for _, set := range sets {
+		converted = append(converted, HardLinkSet{Path: set.FilePath, Links: set.HardLinks, Size: set.FileSize})
+	}
+	return converted
+}
+
+// reportVersion is bumped whenever a field of Report changes meaning or is removed.
+const reportVersion = 1
+
+// NewReport converts duplicate groups, as returned by GetResults, and their summary into a Report.
+func NewReport(groups []models.FileHash, summary ScanSummary) Report {
+	report := Report{
+		Version:     reportVersion,
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Summary:     summary,
+		Groups:      make([]Group, 0, len(groups)),
+	}
+	for _, g := range groups {
+		group := Group{
+			ID:            groupID(g.Hash),
+			Hash:          g.Hash,
+			HashAlgorithm: g.HashAlgorithm,
+			Size:          g.FileSize,
+			Files:         []File{newFile(g, RoleOriginal)},
+		}
+		for _, dup := range g.DuplicatesFound {
+			group.Files = append(group.Files, newFile(dup, RoleDuplicate))
+		}
+		report.Groups = append(report.Groups, group)
+	}
+	return report
+}
+
+func newFile(fh models.FileHash, role string) File {
+	return File{Name: fh.FileName, Path: fh.FilePath, Size: fh.FileSize, ModTime: fh.ModTime, Role: role, HardLinks: fh.HardLinks}
+}
+
+// groupID derives the group ID from the content hash, so the same set of
+// identical files gets the same ID in every run.
+func groupID(hash string) string {
+	if len(hash) > 16 {
+		return hash[:16]
+	}
+	return hash
+}
+
+// Save writes report into dir once per format, all files sharing one timestamped name,
+// and returns the paths written.
+func Save(dir string, report Report, formats []string, opts Options) ([]string, error) {
+	name := common.Results_file_name + time.Now().Format("_2006_01_02_15_04_05")
+
+	var paths []string
+	for _, format := range Normalize(formats) {
+		w, err := Get(format)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, name+"."+w.Extension())
+		if err := writeFile(path, w, report, opts); err != nil {
+			return paths, err
+		}
+		log.InfoWithFuncName(fmt.Sprintf("Results written to: %s", path))
+		paths = append(paths, path)
+
+		if sw, ok := w.(summaryWriter); ok {
+			path := filepath.Join(dir, name+summarySuffix+"."+w.Extension())
+			if err := writeFile(path, summaryOnly{sw}, report, opts); err != nil {
+				return paths, err
+			}
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// summarySuffix is appended to the name of the separate summary file of a format.
+const summarySuffix = "_summary"
+
+// summaryWriter is implemented by formats whose results layout has no room for
+// the summary. Save writes the summary of those into a separate file.
+type summaryWriter interface {
+	Writer
+	WriteSummary(w io.Writer, report Report, opts Options) error
+}
+
+// summaryOnly adapts a summaryWriter to write nothing but the summary.
+type summaryOnly struct {
+	summaryWriter
+}
+
+func (s summaryOnly) Write(w io.Writer, report Report, opts Options) error {
+	return s.WriteSummary(w, report, opts)
+}
+
+func writeFile(path string, w Writer, report Report, opts Options) error {
+	file, err := os.Create(path)
+	if err != nil {
+		log.ErrorWithFuncName(fmt.Sprintf("Failed to create results file [%s]: %v", path, err))
+		return err
+	}
+	if err := w.Write(file, report, opts); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s results: %w", w.Format(), err)
+	}
+	return file.Close()
+}
+
+// Exists reports whether dir contains a results file of any format.
+func Exists(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.WarnWithFuncName(fmt.Sprintf("Error reading directory: %v", err))
+		return false
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), common.Results_file_name) {
+			continue
+		}
+		for _, w := range writers {
+			if strings.HasSuffix(entry.Name(), "."+w.Extension()) {
+				return true
+			}
+		}
+	}
+	return false
+}
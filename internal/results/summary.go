@@ -0,0 +1,143 @@
+package results
+
+import (
+	"DuDe/internal/models"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// largestGroupsLimit is the number of groups ScanSummary.LargestGroups lists.
+const largestGroupsLimit = 10
+
+// ScanSummary sums up an execution. Duplicate counts exclude the one original kept per group,
+// so DuplicateFiles and ReclaimableBytes are what removing every duplicate would free.
+type ScanSummary struct {
+	FilesScanned     int64          `json:"filesScanned"`
+	BytesScanned     int64          `json:"bytesScanned"`
+	DuplicateGroups  int            `json:"duplicateGroups"`
+	DuplicateFiles   int            `json:"duplicateFiles"`
+	ReclaimableBytes int64          `json:"reclaimableBytes"`
+	LargestGroups    []GroupSummary `json:"largestGroups"` // by reclaimable bytes, at most 10
+	ByExtension      []Breakdown    `json:"byExtension"`   // by reclaimable bytes
+	ByDirectory      []Breakdown    `json:"byDirectory"`   // per top-level directory of the scan roots, by reclaimable bytes
+}
+
+// GroupSummary describes one duplicate group.
+type GroupSummary struct {
+	ID               string `json:"id"`
+	Original         string `json:"original"`
+	Files            int    `json:"files"`
+	Size             int64  `json:"size"`
+	ReclaimableBytes int64  `json:"reclaimableBytes"`
+}
+
+// Breakdown sums up the duplicates sharing a key, such as an extension or a directory.
+type Breakdown struct {
+	Key              string `json:"key"`
+	DuplicateFiles   int    `json:"duplicateFiles"`
+	ReclaimableBytes int64  `json:"reclaimableBytes"`
+}
+
+// noExtension is the ByExtension key of files without an extension.
+const noExtension = "(none)"
+
+// Summarize computes the summary of groups found among filesScanned files
+// totalling bytesScanned bytes below roots.
+func Summarize(groups []models.FileHash, roots []string, filesScanned, bytesScanned int64) ScanSummary {
+	summary := ScanSummary{
+		FilesScanned:    filesScanned,
+		BytesScanned:    bytesScanned,
+		DuplicateGroups: len(groups),
+	}
+
+	byExtension := make(map[string]*Breakdown)
+	byDirectory := make(map[string]*Breakdown)
+	add := func(index map[string]*Breakdown, key string, size int64) {
+		if index[key] == nil {
+			index[key] = &Breakdown{Key: key}
+		}
+		index[key].DuplicateFiles++
+		index[key].ReclaimableBytes += size
+	}
+
+	for _, g := range groups {
+		var reclaimable int64
+		for _, dup := range g.DuplicatesFound {
+			reclaimable += reclaimableBytes(dup)
+		}
+		summary.DuplicateFiles += len(g.DuplicatesFound)
+		summary.ReclaimableBytes += reclaimable
+		summary.LargestGroups = append(summary.LargestGroups, GroupSummary{
+			ID:               groupID(g.Hash),
+			Original:         g.FilePath,
+			Files:            len(g.DuplicatesFound) + 1,
+			Size:             g.FileSize,
+			ReclaimableBytes: reclaimable,
+		})
+
+		for _, dup := range g.DuplicatesFound {
+			ext := strings.ToLower(filepath.Ext(dup.FileName))
+			if ext == "" {
+				ext = noExtension
+			}
+			add(byExtension, ext, reclaimableBytes(dup))
+			add(byDirectory, topLevelDir(dup.FilePath, roots), reclaimableBytes(dup))
+		}
+	}
+
+	slices.SortStableFunc(summary.LargestGroups, func(a, b GroupSummary) int {
+		if c := compareDesc(a.ReclaimableBytes, b.ReclaimableBytes); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Original, b.Original)
+	})
+	if len(summary.LargestGroups) > largestGroupsLimit {
+		summary.LargestGroups = summary.LargestGroups[:largestGroupsLimit]
+	}
+	summary.ByExtension = sortedBreakdown(byExtension)
+	summary.ByDirectory = sortedBreakdown(byDirectory)
+
+	return summary
+}
+
+// reclaimableBytes returns the bytes removing dup frees: none while a hard link
+// outside the scan keeps its data on disk.
+func reclaimableBytes(dup models.FileHash) int64 {
+	if dup.ExternalLinks > 0 {
+		return 0
+	}
+	return dup.FileSize
+}
+
+// topLevelDir returns the directory directly below the scan root containing path,
+// the root itself for files directly in it, or the parent directory of path
+// if no root contains it.
+func topLevelDir(path string, roots []string) string {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		first, _, nested := strings.Cut(rel, string(filepath.Separator))
+		if !nested {
+			return filepath.Clean(root)
+		}
+		return filepath.Join(root, first)
+	}
+	return filepath.Dir(path)
+}
+
+func sortedBreakdown(index map[string]*Breakdown) []Breakdown {
+	result := make([]Breakdown, 0, len(index))
+	for _, b := range index {
+		result = append(result, *b)
+	}
+	slices.SortFunc(result, func(a, b Breakdown) int {
+		if c := compareDesc(a.ReclaimableBytes, b.ReclaimableBytes); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return result
+}
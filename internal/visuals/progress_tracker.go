@@ -17,6 +17,9 @@ type ProgressTracker struct {
 	Name                  string
 	BarLength             int
 	totalFiles            int64
+	totalSealed           int32 // set once the total is final, the tracker finishes when progress reaches it
+	totalBytes            int64
+	currentBytes          int64
 	currentProgress       int64
 	lastDisplayedProgress int
 	wg                    sync.WaitGroup
@@ -45,36 +48,56 @@ func (pt *ProgressTracker) updateProgressBarLoop(name string) {
 			curr := float64(atomic.LoadInt64(&pt.currentProgress))
 			tot := float64(atomic.LoadInt64(&pt.totalFiles))
 
-			isItTheStart := curr == 0
 			if curr == 0 {
 				percentage = 0
 			} else {
 				percentage = curr / tot * 100
-				isItTheStart = false
 			}
 			pt.Reporter.LogProgress(pt.Context, name, float64(percentage))
 			pt.Reporter.LogFilesCount(pt.Context, int64(curr), int64(tot))
+			if totBytes := atomic.LoadInt64(&pt.totalBytes); totBytes > 0 {
+				pt.Reporter.LogBytesCount(pt.Context, atomic.LoadInt64(&pt.currentBytes), totBytes)
+			}
 
 			progress := int(float64(pt.BarLength) * percentage / 100)
 			pt.lastDisplayedProgress = progress
 
-			if curr == tot && !isItTheStart {
+			if curr == tot && atomic.LoadInt32(&pt.totalSealed) == 1 {
 				return
 			}
 		}
 	}
 }
 
+// AddTotal adds count to the total and marks it as final.
 func (pt *ProgressTracker) AddTotal(count int64) {
 	atomic.AddInt64(&pt.totalFiles, count)
+	pt.SealTotal()
 }
 
-func (pt *ProgressTracker) Increment() {
-	atomic.AddInt64(&pt.currentProgress, 1)
+// AddPending grows a total that is still unknown, e.g. while files are being streamed in.
+// The tracker keeps running until SealTotal is called.
+func (pt *ProgressTracker) AddPending(count int64) {
+	atomic.AddInt64(&pt.totalFiles, count)
+}
+
+// SealTotal marks the total as final.
+func (pt *ProgressTracker) SealTotal() {
+	atomic.StoreInt32(&pt.totalSealed, 1)
 }
 
-func (pt *ProgressTracker) DecrementFromTotal() {
-	atomic.AddInt64(&pt.totalFiles, -1)
+// AddPendingBytes grows the number of bytes expected to be read.
+func (pt *ProgressTracker) AddPendingBytes(count int64) {
+	atomic.AddInt64(&pt.totalBytes, count)
+}
+
+// AddBytes records bytes read, so progress inside very large files stays visible.
+func (pt *ProgressTracker) AddBytes(count int64) {
+	atomic.AddInt64(&pt.currentBytes, count)
+}
+
+func (pt *ProgressTracker) Increment() {
+	atomic.AddInt64(&pt.currentProgress, 1)
 }
 
 func (pt *ProgressTracker) Wait() {
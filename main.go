@@ -1,10 +1,12 @@
 package main
 
 import (
+	"DuDe/internal/cli"
 	"DuDe/internal/processing"
 	"DuDe/internal/reporting"
 
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -17,6 +19,11 @@ var assets embed.FS
 
 func main() {
 
+	// Headless mode: `dude scan ...` runs without starting the GUI.
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	wailsReporter := reporting.WailsReporter{}
 	app := processing.NewApp(&wailsReporter)
 
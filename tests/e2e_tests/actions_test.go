@@ -0,0 +1,413 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/actions"
+	"DuDe/internal/models"
+	process "DuDe/internal/processing"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scanForActions creates files under a fresh directory and runs an execution over it.
+func scanForActions(t *testing.T, files map[string][]byte) (*process.FrontendApp, string) {
+	t.Helper()
+	app := setupTestApp(t)
+
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories: []string{tempDir},
+		ResultsDir:  outDir,
+		CacheDir:    outDir,
+		CPUs:        1,
+		BufSize:     1024,
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	if len(app.GetResults()) != 1 {
+		t.Fatalf("Expected 1 duplicate group, got %+v", app.GetResults())
+	}
+	return app, tempDir
+}
+
+// remaining returns which of names still exist below dir.
+func remaining(dir string, names ...string) []string {
+	var found []string
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+var actionTestFiles = map[string][]byte{
+	"a.txt":     []byte("duplicate content"),
+	"sub/b.txt": []byte("duplicate content"),
+	"sub/c.txt": []byte("duplicate content"),
+	"u.txt":     []byte("unique content"),
+}
+
+func Test_Actions_DeleteKeepsOriginal(t *testing.T) {
+	app, dir := scanForActions(t, actionTestFiles)
+	original := app.GetResults()[0].FilePath
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Delete})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 || report.FilesFailed != 0 || report.BytesFreed != 2*int64(len("duplicate content")) {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if _, err := os.Stat(original); err != nil {
+		t.Errorf("Original %s must be kept: %v", original, err)
+	}
+	if left := remaining(dir, "a.txt", "sub/b.txt", "sub/c.txt", "u.txt"); len(left) != 2 {
+		t.Errorf("Expected the original and the unique file to remain, got %v", left)
+	}
+	if len(app.GetResults()) != 0 {
+		t.Errorf("Expected acted-on groups to be pruned from the results, got %+v", app.GetResults())
+	}
+}
+
+func Test_Actions_DryRunTouchesNothing(t *testing.T) {
+	app, dir := scanForActions(t, actionTestFiles)
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Delete, DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 {
+		t.Errorf("Expected 2 files reported, got %+v", report)
+	}
+	if left := remaining(dir, "a.txt", "sub/b.txt", "sub/c.txt"); len(left) != 3 {
+		t.Errorf("Dry run must not remove files, remaining %v", left)
+	}
+	if len(app.GetResults()) != 1 {
+		t.Errorf("Dry run must not prune the results")
+	}
+}
+
+func Test_Actions_QuarantinePreservesAbsolutePaths(t *testing.T) {
+	app, _ := scanForActions(t, actionTestFiles)
+	original := app.GetResults()[0].FilePath
+	quarantine := t.TempDir()
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Quarantine, QuarantineDir: quarantine})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 {
+		t.Fatalf("Expected 2 files quarantined, got %+v", report)
+	}
+
+	for _, o := range report.Outcomes {
+		want := filepath.Join(quarantine, strings.TrimPrefix(o.Path, filepath.VolumeName(o.Path)))
+		if o.Destination != want {
+			t.Errorf("Expected %s to be quarantined at %s, got %s", o.Path, want, o.Destination)
+		}
+		if content, err := os.ReadFile(want); err != nil || string(content) != "duplicate content" {
+			t.Errorf("Quarantined file %s missing or changed: %v", want, err)
+		}
+		if _, err := os.Stat(o.Path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected %s to be moved away", o.Path)
+		}
+	}
+	if _, err := os.Stat(original); err != nil {
+		t.Errorf("Original %s must be kept: %v", original, err)
+	}
+}
+
+// Test_Actions_QuarantineKeepsRootsWithTheSameName verifies that the files of two scan roots
+// with the same name do not collide in the quarantine directory, and can be restored.
+func Test_Actions_QuarantineKeepsRootsWithTheSameName(t *testing.T) {
+	base, cleanup := createTestFilesByteArray(t, map[string][]byte{
+		"a/photos/img.jpg": []byte("picture"),
+		"b/photos/img.jpg": []byte("picture"),
+		"c/photos/img.jpg": []byte("picture"),
+	})
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+	roots := []string{filepath.Join(base, "a", "photos"), filepath.Join(base, "b", "photos"), filepath.Join(base, "c", "photos")}
+
+	app := setupTestApp(t)
+	outDir := t.TempDir()
+	args := models.ExecutionParams{Directories: roots, ResultsDir: outDir, CacheDir: outDir, CPUs: 1, BufSize: 1024}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Quarantine, QuarantineDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 || report.FilesFailed != 0 {
+		t.Fatalf("Expected both duplicates quarantined, got %+v", report)
+	}
+	if report.Outcomes[0].Destination == report.Outcomes[1].Destination {
+		t.Errorf("Expected distinct quarantine paths, got %s twice", report.Outcomes[0].Destination)
+	}
+
+	restored, err := app.RestoreFromJournal(actions.RestoreRequest{})
+	if err != nil || restored.FilesActed != 2 {
+		t.Fatalf("Expected both duplicates restored, got %+v (%v)", restored, err)
+	}
+	for _, root := range roots {
+		if content, err := os.ReadFile(filepath.Join(root, "img.jpg")); err != nil || string(content) != "picture" {
+			t.Errorf("Expected %s to be back: %v", root, err)
+		}
+	}
+}
+
+func Test_Actions_QuarantineRequiresDirectory(t *testing.T) {
+	app, _ := scanForActions(t, actionTestFiles)
+
+	if _, err := app.ApplyAction(actions.Request{Action: actions.Quarantine}); !errors.Is(err, actions.ErrNoQuarantineDir) {
+		t.Errorf("Expected ErrNoQuarantineDir, got %v", err)
+	}
+	if _, err := app.ApplyAction(actions.Request{Action: "shred"}); !errors.Is(err, actions.ErrUnknownAction) {
+		t.Errorf("Expected ErrUnknownAction, got %v", err)
+	}
+}
+
+// Test_Actions_CacheFromAnotherTimeZone verifies that duplicates whose modification times
+// come from a cache written in another time zone are still recognised as unchanged.
+func Test_Actions_CacheFromAnotherTimeZone(t *testing.T) {
+	local := time.Local
+	t.Cleanup(func() { time.Local = local })
+
+	dir, cleanup := createTestFilesByteArray(t, actionTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+	outDir := t.TempDir()
+	args := models.ExecutionParams{Directories: []string{dir}, ResultsDir: outDir, CacheDir: outDir, UseCache: true, CPUs: 1, BufSize: 1024}
+
+	time.Local = time.UTC
+	if err := setupTestApp(t).StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	time.Local = time.FixedZone("UTC+9", 9*60*60)
+	app := setupTestApp(t)
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	report, err := app.ApplyAction(actions.Request{Action: actions.Delete, DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 || report.FilesFailed != 0 {
+		t.Errorf("Expected both duplicates to verify, got %+v", report)
+	}
+}
+
+// Test_Actions_StaleFilesAreNeverTouched verifies that a duplicate modified after the scan,
+// and every duplicate of an original modified after the scan, is skipped.
+func Test_Actions_StaleFilesAreNeverTouched(t *testing.T) {
+	app, dir := scanForActions(t, actionTestFiles)
+	group := app.GetResults()[0]
+
+	// Same size, different content: only the hash check can catch this.
+	changed := group.DuplicatesFound[0].FilePath
+	if err := os.WriteFile(changed, []byte("DUPLICATE CONTENT"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Delete})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 1 || report.FilesFailed != 1 {
+		t.Fatalf("Expected 1 deleted and 1 skipped file, got %+v", report)
+	}
+	if _, err := os.Stat(changed); err != nil {
+		t.Errorf("Changed file %s must not be deleted: %v", changed, err)
+	}
+	for _, o := range report.Outcomes {
+		if o.Path == changed && !strings.Contains(o.Error, actions.ErrFileChanged.Error()) {
+			t.Errorf("Expected a file changed error, got %q", o.Error)
+		}
+	}
+	if len(app.GetResults()) != 1 || len(app.GetResults()[0].DuplicatesFound) != 1 {
+		t.Errorf("Expected the skipped duplicate to stay in the results, got %+v", app.GetResults())
+	}
+
+	// Now change the original: its remaining duplicate must be kept as well.
+	if err := os.WriteFile(group.FilePath, []byte("changed original!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report, err = app.ApplyAction(actions.Request{Action: actions.Delete})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 0 {
+		t.Errorf("Expected nothing to be deleted when the original changed, got %+v", report)
+	}
+	if left := remaining(dir, "a.txt", "sub/b.txt", "sub/c.txt"); len(left) != 2 {
+		t.Errorf("Expected 2 files to remain, got %v", left)
+	}
+}
+
+func Test_Actions_TrashFollowsFreedesktopSpec(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("trash is only supported on Linux")
+	}
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	// Both duplicates share a base name, so the second one must get a new trash name.
+	app, _ := scanForActions(t, map[string][]byte{
+		"a.txt":      []byte("duplicate content"),
+		"x/a.txt":    []byte("duplicate content"),
+		"y/z/a.txt":  []byte("duplicate content"),
+		"unique.txt": []byte("unique content"),
+	})
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Trash})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 {
+		t.Fatalf("Expected 2 files trashed, got %+v", report)
+	}
+
+	for _, o := range report.Outcomes {
+		if filepath.Dir(o.Destination) != filepath.Join(dataHome, "Trash", "files") {
+			// Different filesystem than the test data home; the per-volume trash is used.
+			continue
+		}
+		info, err := os.ReadFile(filepath.Join(dataHome, "Trash", "info", filepath.Base(o.Destination)+".trashinfo"))
+		if err != nil {
+			t.Fatalf("Missing .trashinfo for %s: %v", o.Path, err)
+		}
+		if !strings.HasPrefix(string(info), "[Trash Info]\n") || !strings.Contains(string(info), "Path="+o.Path+"\n") ||
+			!strings.Contains(string(info), "DeletionDate=") {
+			t.Errorf("Unexpected .trashinfo content:\n%s", info)
+		}
+	}
+
+	if report.Outcomes[0].Destination == report.Outcomes[1].Destination {
+		t.Errorf("Trashed files must not overwrite each other: %+v", report.Outcomes)
+	}
+}
+
+func Test_Actions_LinkReplacesDuplicatesWithHardLinks(t *testing.T) {
+	app, _ := scanForActions(t, actionTestFiles)
+	group := app.GetResults()[0]
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Link})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 || report.BytesFreed != 2*group.FileSize {
+		t.Fatalf("Expected 2 files linked reclaiming %d bytes, got %+v", 2*group.FileSize, report)
+	}
+
+	originalInfo, err := os.Stat(group.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dup := range group.DuplicatesFound {
+		info, err := os.Lstat(dup.FilePath)
+		if err != nil {
+			t.Fatalf("Linked duplicate %s must still exist: %v", dup.FilePath, err)
+		}
+		if !os.SameFile(originalInfo, info) {
+			t.Errorf("Expected %s to be a hard link to %s", dup.FilePath, group.FilePath)
+		}
+	}
+
+	// No temporary link may be left behind.
+	entries, _ := os.ReadDir(filepath.Dir(group.DuplicatesFound[0].FilePath))
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".dude-") {
+			t.Errorf("Leftover temporary file %s", e.Name())
+		}
+	}
+}
+
+func Test_Actions_LinkedFilesAreNoLongerDuplicates(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("hard links are only detected on Linux")
+	}
+	app, _ := scanForActions(t, actionTestFiles)
+	if _, err := app.ApplyAction(actions.Request{Action: actions.Link}); err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+
+	// A fresh scan collapses the hard links into one file, so there is nothing left to act on.
+	if err := app.StartExecution(app.Args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	if groups := app.GetResults(); len(groups) != 0 {
+		t.Fatalf("Expected hard links not to be reported as duplicates, got %+v", groups)
+	}
+	if _, err := app.ApplyAction(actions.Request{Action: actions.Link}); !errors.Is(err, actions.ErrNoResults) {
+		t.Errorf("Expected ErrNoResults, got %v", err)
+	}
+	if sets := app.GetHardLinks(); len(sets) != 1 || len(sets[0].HardLinks) != 2 {
+		t.Errorf("Expected one file with 3 paths, got %+v", sets)
+	}
+}
+
+func Test_Actions_SymlinkPointsToOriginal(t *testing.T) {
+	app, _ := scanForActions(t, actionTestFiles)
+	group := app.GetResults()[0]
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Symlink})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 {
+		t.Fatalf("Expected 2 files symlinked, got %+v", report)
+	}
+	for _, dup := range group.DuplicatesFound {
+		target, err := os.Readlink(dup.FilePath)
+		if err != nil {
+			t.Fatalf("Expected %s to be a symlink: %v", dup.FilePath, err)
+		}
+		if target != group.FilePath {
+			t.Errorf("Expected %s to point to %s, got %s", dup.FilePath, group.FilePath, target)
+		}
+		if content, err := os.ReadFile(dup.FilePath); err != nil || string(content) != "duplicate content" {
+			t.Errorf("Symlink %s does not resolve to the original content: %v", dup.FilePath, err)
+		}
+	}
+}
+
+func Test_Actions_KeepRulesChooseTheOriginal(t *testing.T) {
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, actionTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories: []string{dir},
+		ResultsDir:  outDir,
+		CacheDir:    outDir,
+		CPUs:        1,
+		BufSize:     1024,
+		KeepRules:   []string{"prefer-dir=" + filepath.Join(dir, "sub"), "longest-path", "name=c.*"},
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	group := app.GetResults()[0]
+	if want := filepath.Join(dir, "sub", "c.txt"); group.FilePath != want {
+		t.Fatalf("Expected %s to be kept, got %s", want, group.FilePath)
+	}
+
+	if _, err := app.ApplyAction(actions.Request{Action: actions.Delete}); err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if left := remaining(dir, "a.txt", "sub/b.txt", "sub/c.txt"); len(left) != 1 || left[0] != "sub/c.txt" {
+		t.Errorf("Expected only sub/c.txt to remain, got %v", left)
+	}
+}
@@ -0,0 +1,222 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/cli"
+	database "DuDe/internal/db"
+	"DuDe/internal/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var cacheTestFiles = map[string][]byte{
+	"a.txt":     []byte("duplicate content"),
+	"sub/b.txt": []byte("duplicate content"),
+	"c.txt":     []byte("another size"),
+}
+
+func Test_Cache_MaintenanceOnFrontendApp(t *testing.T) {
+	dir, cleanup := createTestFilesByteArray(t, cacheTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{Directories: []string{dir}, ResultsDir: outDir, CacheDir: outDir, UseCache: true, CPUs: 1, BufSize: 1024}
+	app := setupTestApp(t)
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	stats, err := app.CacheStats(10)
+	if err != nil {
+		t.Fatalf("CacheStats failed: %v", err)
+	}
+	// c.txt has a unique size, so it is never hashed nor cached.
+	if stats.Entries != 2 || len(stats.Oldest) != 2 || stats.SizeBytes == 0 {
+		t.Errorf("Expected the two hashed files in the cache, got %+v", stats)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "sub", "b.txt")); err != nil {
+		t.Fatal(err)
+	}
+	report, err := app.PruneCache(database.PruneRequest{Missing: true, OlderThanDays: 1})
+	if err != nil {
+		t.Fatalf("PruneCache failed: %v", err)
+	}
+	if report.Missing != 1 || report.Old != 0 || report.EntriesLeft != 1 {
+		t.Errorf("Expected only the deleted file to be pruned, got %+v", report)
+	}
+
+	if stats, err := app.VacuumCache(); err != nil || stats.Entries != 1 {
+		t.Errorf("Expected one entry after vacuuming, got %+v (%v)", stats, err)
+	}
+}
+
+func Test_Cache_RescanMarksFilesSeen(t *testing.T) {
+	dir, cleanup := createTestFilesByteArray(t, cacheTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{Directories: []string{dir}, ResultsDir: outDir, CacheDir: outDir, UseCache: true, CPUs: 1, BufSize: 1024}
+	if err := setupTestApp(t).StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	db, err := database.OpenCache(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE file_hashes SET seen_at = '2000-01-01T00:00:00Z'"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// The files are unchanged, so the rescan reads their hashes from the cache without
+	// rewriting them, but it still walked them.
+	if err := setupTestApp(t).StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	code, out := runCLI(t, "cache", "-cache-dir", outDir, "-older-than", "1", "prune")
+	if code != cli.ExitOK || !strings.Contains(out, "0 not seen for 1 days") {
+		t.Errorf("Expected no entry to be old after the rescan, got exit code %d:\n%s", code, out)
+	}
+}
+
+func Test_CLI_Cache(t *testing.T) {
+	dir, cleanup := createTestFilesByteArray(t, cacheTestFiles)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+	outDir := t.TempDir()
+
+	if code, _ := runCLI(t, "cache", "-cache-dir", outDir, "stats"); code != cli.ExitError {
+		t.Errorf("Expected a missing cache to fail, got exit code %d", code)
+	}
+	if code, out := runCLI(t, "scan", "-quiet", "-results-dir", outDir, "-cache-dir", outDir, dir); code != cli.ExitDuplicates {
+		t.Fatalf("Expected duplicates, got exit code %d: %s", code, out)
+	}
+
+	code, out := runCLI(t, "cache", "-cache-dir", outDir, "stats")
+	if code != cli.ExitOK || !strings.Contains(out, "2 entries") || !strings.Contains(out, filepath.Join(dir, "a.txt")) {
+		t.Errorf("Expected the statistics with the oldest entries, got exit code %d:\n%s", code, out)
+	}
+
+	code, out = runCLI(t, "cache", "-cache-dir", outDir, "-prefix", filepath.Join(dir, "sub"), "-vacuum", "prune")
+	if code != cli.ExitOK || !strings.Contains(out, "1 by prefix; 1 entries left") {
+		t.Errorf("Expected sub/b.txt to be pruned, got exit code %d:\n%s", code, out)
+	}
+
+	if code, _ := runCLI(t, "cache", "-cache-dir", outDir, "prune"); code != cli.ExitError {
+		t.Errorf("Expected prune without criteria to fail, got exit code %d", code)
+	}
+	if code, out := runCLI(t, "cache", "-cache-dir", outDir, "vacuum"); code != cli.ExitOK || !strings.Contains(out, "1 entries") {
+		t.Errorf("Expected vacuum to print the statistics, got exit code %d:\n%s", code, out)
+	}
+}
+
+func Test_CLI_Cache_PruneArguments(t *testing.T) {
+	dir, cleanup := createTestFilesByteArray(t, cacheTestFiles)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+	outDir := t.TempDir()
+	if code, out := runCLI(t, "scan", "-quiet", "-results-dir", outDir, "-cache-dir", outDir, dir); code != cli.ExitDuplicates {
+		t.Fatalf("Expected duplicates, got exit code %d: %s", code, out)
+	}
+
+	// A relative prefix is below the working directory, like a relative scan root.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(wd, filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, out := runCLI(t, "cache", "-cache-dir", outDir, "-prefix", rel+string(filepath.Separator), "prune")
+	if code != cli.ExitOK || !strings.Contains(out, "1 by prefix; 1 entries left") {
+		t.Errorf("Expected sub/b.txt to be pruned by its relative directory, got exit code %d:\n%s", code, out)
+	}
+
+	// Flags may follow the command.
+	if err := os.Remove(filepath.Join(dir, "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	code, out = runCLI(t, "cache", "prune", "-cache-dir", outDir, "-missing")
+	if code != cli.ExitOK || !strings.Contains(out, "Removed 1 entries of missing files") {
+		t.Errorf("Expected a.txt to be pruned, got exit code %d:\n%s", code, out)
+	}
+}
+
+func Test_Cache_ExportImportOnAnotherRoot(t *testing.T) {
+	files := map[string][]byte{
+		"a.txt":     []byte("duplicate content"),
+		"b.txt":     []byte("duplicate content"),
+		"sub/c.txt": []byte("duplicate content"),
+	}
+	source, cleanup := createTestFilesByteArray(t, files)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+	sourceCache, export := t.TempDir(), filepath.Join(t.TempDir(), "cache.ndjson")
+
+	if code, out := runCLI(t, "scan", "-quiet", "-results-dir", sourceCache, "-cache-dir", sourceCache, source); code != cli.ExitDuplicates {
+		t.Fatalf("Expected duplicates, got exit code %d: %s", code, out)
+	}
+	if code, out := runCLI(t, "cache", "-cache-dir", sourceCache, "-root", source, "export", export); code != cli.ExitOK || !strings.Contains(out, "Exported 3 entries") {
+		t.Fatalf("Expected the three hashed files to be exported, got exit code %d:\n%s", code, out)
+	}
+
+	// The copy keeps sizes and modification times, but b.txt was edited in place since.
+	target, cleanupTarget := createTestFilesByteArray(t, files)
+	t.Cleanup(cleanupTarget)
+	for name := range files {
+		info, err := os.Stat(filepath.Join(source, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "b.txt" {
+			if err := os.WriteFile(filepath.Join(target, name), []byte("different content"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.Chtimes(filepath.Join(target, name), info.ModTime(), info.ModTime()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	targetCache := t.TempDir()
+	app := setupTestApp(t)
+	app.Args.CacheDir = targetCache
+	report, err := app.ImportCache(export, target)
+	if err != nil || report.Imported != 3 || report.Skipped != 0 {
+		t.Fatalf("Expected three imported entries, got %+v (%v)", report, err)
+	}
+
+	// Marking the imported hashes shows which ones the scan used instead of hashing.
+	db, err := database.OpenCache(targetCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE file_hashes SET hash = 'imported-' || hash"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	args := models.ExecutionParams{Directories: []string{target}, ResultsDir: targetCache, CacheDir: targetCache, UseCache: true, CPUs: 1, BufSize: 1024}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	groups := app.GetResults()
+	if got, want := groupMembers(t, target, groups), []string{"a.txt", "sub/c.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Expected the edited b.txt not to be trusted, got duplicates %v", got)
+	}
+	if len(groups) != 1 || !strings.HasPrefix(groups[0].Hash, "imported-") {
+		t.Errorf("Expected the imported hash to be used instead of hashing, got %+v", groups)
+	}
+
+	db, err = database.OpenCache(targetCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var imported int
+	if err := db.QueryRow("SELECT COUNT(*) FROM file_hashes WHERE imported != 0").Scan(&imported); err != nil || imported != 0 {
+		t.Errorf("Expected the scan to make every entry its own, %d still imported (%v)", imported, err)
+	}
+}
@@ -0,0 +1,106 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/handlers/validation"
+	"DuDe/internal/models"
+	process "DuDe/internal/processing"
+	"DuDe/internal/reporting"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test_Cancel_LargeFileHashing verifies that cancelling stops the hashing of very large
+// files within a bounded time instead of waiting for the reads to finish.
+func Test_Cancel_LargeFileHashing(t *testing.T) {
+	dir := t.TempDir()
+
+	// Two identical, sparse 4 GiB files: cheap to create, slow to hash.
+	const size = 4 << 30
+	for _, name := range []string{"big1.bin", "big2.bin"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			t.Skipf("sparse files not supported here: %v", err)
+		}
+		f.Close()
+	}
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories: []string{dir},
+		ResultsDir:  outDir,
+		CacheDir:    outDir,
+		CPUs:        2,
+		BufSize:     1024,
+	}
+	resolver := validation.Resolver{V: validation.MockValidator{}}
+	if err := resolver.ResolveAndValidateArgs(&args, outDir); err != nil {
+		t.Fatalf("failed to resolve args: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(500*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := process.Execute(ctx, args, reporting.NoOpReporter{})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed > 3*time.Second {
+		t.Errorf("Expected cancellation within 3s, took %s", elapsed)
+	}
+}
+
+// Test_Cancel_WithCache verifies that cancelling a scan of many small files with the
+// cache enabled returns cleanly: no stage may still write to the cache or report an error
+// once the execution closed their channels.
+func Test_Cancel_WithCache(t *testing.T) {
+	dir := t.TempDir()
+
+	// Files of one size with unique content: each passes the size stage and is then
+	// dropped and cached by the partial stage.
+	for i := range 20000 {
+		name := filepath.Join(dir, fmt.Sprintf("%02d", i%100), fmt.Sprintf("file%05d.txt", i))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(fmt.Sprintf("content %05d", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, delay := range []time.Duration{10 * time.Millisecond, 50 * time.Millisecond, 150 * time.Millisecond} {
+		outDir := t.TempDir()
+		args := models.ExecutionParams{
+			Directories: []string{dir},
+			ResultsDir:  outDir,
+			CacheDir:    outDir,
+			UseCache:    true,
+			CPUs:        4,
+			BufSize:     1024,
+		}
+		resolver := validation.Resolver{V: validation.MockValidator{}}
+		if err := resolver.ResolveAndValidateArgs(&args, outDir); err != nil {
+			t.Fatalf("failed to resolve args: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(delay, cancel)
+
+		// A scan finishing before the cancel is fine, a panic is not.
+		if _, err := process.Execute(ctx, args, reporting.NoOpReporter{}); err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("Cancel after %s: expected context.Canceled, got %v", delay, err)
+		}
+		cancel()
+	}
+}
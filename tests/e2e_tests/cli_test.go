@@ -0,0 +1,115 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/cli"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runCLI runs the headless CLI with the given args and returns its exit code and stdout.
+func runCLI(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := cli.Run(args, &stdout, &stderr)
+	t.Logf("stderr: %s", stderr.String())
+	return code, stdout.String()
+}
+
+func Test_CLI_Scan_NoDuplicates(t *testing.T) {
+	files := map[string][]byte{
+		"a.txt": []byte("content A"),
+		"b.txt": []byte("content B"),
+	}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+
+	code, _ := runCLI(t, "scan", "-quiet", "-results-dir", outDir, "-cache-dir", outDir, tempDir)
+	if code != cli.ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitOK, code)
+	}
+
+	if _, err := readResultsFile(t, outDir); err == nil {
+		t.Error("Expected no results file, but found one")
+	}
+}
+
+func Test_CLI_Scan_WithDuplicates(t *testing.T) {
+	files := map[string][]byte{
+		"a.txt":     []byte("duplicate content"),
+		"sub/b.txt": []byte("duplicate content"),
+		"c.txt":     []byte("unique content"),
+	}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+
+	code, out := runCLI(t, "scan", "-quiet", "-paranoid", "-results-dir", outDir, "-cache-dir", outDir, tempDir)
+	if code != cli.ExitDuplicates {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitDuplicates, code)
+	}
+
+	csvLines, err := readResultsFile(t, outDir)
+	if err != nil {
+		t.Fatal("Failed to read CSV data:", err)
+	}
+	csvContainsExpected(t, csvLines, []string{"a.txt", "b.txt"})
+
+	for _, want := range []string{"Scanned 3 files (48 B). 1 duplicate files in 1 groups, 17 B reclaimable.", "Largest groups:", "By extension:", ".txt"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the summary, got:\n%s", want, out)
+		}
+	}
+}
+
+func Test_CLI_Scan_InvalidDirectory(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "does-not-exist")
+
+	code, _ := runCLI(t, "scan", "-quiet", "-cache=false", "-results-dir", os.TempDir(), missing)
+	if code != cli.ExitError {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitError, code)
+	}
+}
+
+func Test_CLI_UnknownCommand(t *testing.T) {
+	code, _ := runCLI(t, "frobnicate")
+	if code != cli.ExitError {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitError, code)
+	}
+}
+
+func Test_CLI_Scan_QuarantineAction(t *testing.T) {
+	files := map[string][]byte{
+		"a.txt":     []byte("duplicate content"),
+		"sub/b.txt": []byte("duplicate content"),
+	}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+	quarantine := t.TempDir()
+
+	code, stdout := runCLI(t, "scan", "-quiet", "-results-dir", outDir, "-cache-dir", outDir,
+		"-action", "quarantine", "-quarantine-dir", quarantine, tempDir)
+	if code != cli.ExitDuplicates {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitDuplicates, code)
+	}
+	if !strings.Contains(stdout, "Applied quarantine to 1 files") {
+		t.Errorf("Expected an action summary, got %q", stdout)
+	}
+	if left := remaining(tempDir, "a.txt", "sub/b.txt"); len(left) != 1 {
+		t.Errorf("Expected exactly one copy to remain, got %v", left)
+	}
+}
+
+func Test_CLI_Scan_InvalidAction(t *testing.T) {
+	code, _ := runCLI(t, "scan", "-quiet", "-action", "quarantine", t.TempDir())
+	if code != cli.ExitError {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitError, code)
+	}
+}
@@ -0,0 +1,139 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/cli"
+	"DuDe/internal/models"
+	"DuDe/internal/results"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var directoryTestFiles = map[string][]byte{
+	"photos/a.jpg":             []byte("picture a"),
+	"photos/b.jpg":             []byte("picture b"),
+	"photos/2024/c.jpg":        []byte("picture c"),
+	"backup/photos/a.jpg":      []byte("picture a"),
+	"backup/photos/b.jpg":      []byte("picture b"),
+	"backup/photos/2024/c.jpg": []byte("picture c"),
+	"old/a.jpg":                []byte("picture a"),
+	"old/2024/c.jpg":           []byte("picture c"),
+	"mixed/a.jpg":              []byte("picture a"),
+	"mixed/notes.txt":          []byte("only here"),
+}
+
+// relPaths returns paths relative to dir, slash-separated.
+func relPaths(t *testing.T, dir string, paths ...string) []string {
+	t.Helper()
+	var rel []string
+	for _, path := range paths {
+		r, err := filepath.Rel(dir, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return rel
+}
+
+// countLineTypes returns the number of lines of each type in an NDJSON results file.
+func countLineTypes(t *testing.T, path string) map[string]int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var typed struct{ Type string }
+		if err := json.Unmarshal([]byte(line), &typed); err != nil {
+			t.Fatalf("Line %q is not JSON: %v", line, err)
+		}
+		counts[typed.Type]++
+	}
+	return counts
+}
+
+func Test_Directories_IdenticalAndSubsets(t *testing.T) {
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, directoryTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:   []string{dir},
+		ResultsDir:    outDir,
+		CacheDir:      outDir,
+		CPUs:          1,
+		BufSize:       1024,
+		CompareDirs:   true,
+		ResultFormats: []string{"ndjson"},
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	// The 2024 directories of the photo copies are covered by their parents' set, but
+	// old/2024 is a copy as well, so the three are a set of their own.
+	var sets [][]string
+	for _, set := range app.GetDuplicateDirs() {
+		sets = append(sets, relPaths(t, dir, set.Paths...))
+	}
+	want := [][]string{{"backup/photos", "photos"}, {"backup/photos/2024", "old/2024", "photos/2024"}}
+	if !slices.EqualFunc(sets, want, slices.Equal) {
+		t.Errorf("Expected the directory sets %v, got %v", want, sets)
+	}
+	if got := app.GetDuplicateDirs(); len(got) > 0 && (got[0].Files != 3 || got[0].Size != 27) {
+		t.Errorf("Expected 3 files of 27 bytes in each photo directory, got %+v", got[0])
+	}
+
+	// old has a copy of each of its files in both photo directories; mixed has a file of its own.
+	var subsets []string
+	for _, subset := range app.GetSubsetDirs() {
+		subsets = append(subsets, strings.Join(relPaths(t, dir, subset.Path, subset.Superset), " in "))
+	}
+	if want := []string{"old in backup/photos", "old in photos"}; !slices.Equal(subsets, want) {
+		t.Errorf("Expected the subsets %v, got %v", want, subsets)
+	}
+
+	written, err := filepath.Glob(filepath.Join(outDir, "results_*.ndjson"))
+	if err != nil || len(written) != 1 {
+		t.Fatalf("Expected an NDJSON results file, got %v (%v)", written, err)
+	}
+	lines := countLineTypes(t, written[0])
+	if lines[results.LineDirs] != 2 || lines[results.LineSubset] != 2 {
+		t.Errorf("Expected the directories in the results, got %v", lines)
+	}
+}
+
+func Test_Directories_NotComparedByDefault(t *testing.T) {
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, directoryTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{Directories: []string{dir}, ResultsDir: outDir, CacheDir: outDir, CPUs: 1, BufSize: 1024}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	if len(app.GetDuplicateDirs()) != 0 || len(app.GetSubsetDirs()) != 0 {
+		t.Errorf("Expected no directory comparison, got %+v and %+v", app.GetDuplicateDirs(), app.GetSubsetDirs())
+	}
+}
+
+func Test_CLI_Scan_Directories(t *testing.T) {
+	dir, cleanup := createTestFilesByteArray(t, directoryTestFiles)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+	outDir := t.TempDir()
+
+	code, out := runCLI(t, "scan", "-quiet", "-dirs", "-results-dir", outDir, "-cache-dir", outDir, dir)
+	if code != cli.ExitDuplicates {
+		t.Fatalf("Expected duplicates, got exit code %d: %s", code, out)
+	}
+	if !strings.Contains(out, "2 sets of identical directories") || !strings.Contains(out, filepath.Join(dir, "old")+" (2 files) in "+filepath.Join(dir, "photos")) {
+		t.Errorf("Expected the directories to be listed, got:\n%s", out)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"DuDe/internal/common"
 	process "DuDe/internal/processing"
 	"DuDe/internal/reporting"
+	"DuDe/internal/results"
 	"context"
 	"crypto/rand"
 	"encoding/csv"
@@ -150,8 +151,8 @@ func readResultsFile(t *testing.T, dir string) ([][]string, error) {
 
 		name := entry.Name()
 
-		// Check if file starts with "results" and ends with ".csv"
-		if strings.HasPrefix(name, "results") && strings.HasSuffix(name, ".csv") {
+		// Check if file starts with "results" and ends with ".csv", skipping the summary next to it
+		if strings.HasPrefix(name, "results") && strings.HasSuffix(name, ".csv") && !strings.HasSuffix(name, "_summary.csv") {
 			filePath = filepath.Join(dir, name)
 		}
 	}
@@ -171,7 +172,7 @@ func readResultsFile(t *testing.T, dir string) ([][]string, error) {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
-	reader.Comma = process.GetDelimiterForOS()
+	reader.Comma = results.DefaultCSVDelimiter
 	allCsvLines, err := reader.ReadAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read CSV data from %q: %w", filePath, err)
@@ -198,14 +199,21 @@ func csvContainsExpected(t *testing.T, allCsvLines [][]string, expectedWords []s
 	}
 }
 
-// csvContainsNumberOfRowsExpected counts the number of data rows in the provided CSV content.
-// A row is considered a data row if it does not contain the common.ResultsFileSeparator,
-// and it is not a header row as identified by common.ResultsHeader.
-// It reports a test error if the counted number of data rows does not match the expectedRows.
+// csvContainsNumberOfRowsExpected counts the duplicate rows in the provided CSV content.
+// In the legacy pair layout every row that is neither a separator (common.ResultsFileSeperator)
+// nor the header (common.ResultsHeader) is a duplicate; in the file layout (common.ResultsHeaderV2)
+// every row with the duplicate role is.
+// It reports a test error if the counted number of duplicate rows does not match the expectedRows.
 func csvContainsNumberOfRowsExpected(t *testing.T, allCsvLines [][]string, expectedRows int) {
 	t.Helper()
 	found := 0
 	for _, line := range allCsvLines {
+		if len(line) == len(common.ResultsHeaderV2) {
+			if line[1] == results.RoleDuplicate {
+				found++
+			}
+			continue
+		}
 
 		if !slices.Contains(line, common.ResultsFileSeperator) &&
 			!slices.Equal(line[len(line)-3:], common.ResultsHeader[len(common.ResultsHeader)-3:]) {
@@ -0,0 +1,184 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/cli"
+	"DuDe/internal/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// scanWithParams creates files, runs an execution over them with args and returns the
+// base directory and the relative, slash-separated paths of every file in a duplicate group.
+func scanWithParams(t *testing.T, files map[string][]byte, args models.ExecutionParams) (string, []string) {
+	t.Helper()
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, files)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args.Directories = []string{dir}
+	args.ResultsDir = outDir
+	args.CacheDir = outDir
+	args.CPUs = 1
+	args.BufSize = 1024
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	return dir, groupMembers(t, dir, app.GetResults())
+}
+
+// groupMembers returns the sorted paths of every file in groups, relative to dir.
+func groupMembers(t *testing.T, dir string, groups []models.FileHash) []string {
+	t.Helper()
+	var members []string
+	for _, g := range groups {
+		for _, f := range append([]models.FileHash{g}, g.DuplicatesFound...) {
+			rel, err := filepath.Rel(dir, f.FilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			members = append(members, filepath.ToSlash(rel))
+		}
+	}
+	slices.Sort(members)
+	return members
+}
+
+var filterTestFiles = map[string][]byte{
+	"a.jpg":                  []byte("picture"),
+	"copy/a.jpg":             []byte("picture"),
+	"node_modules/a.jpg":     []byte("picture"),
+	"b.txt":                  []byte("some text"),
+	"copy/b.TXT":             []byte("some text"),
+	"big.bin":                []byte("a much larger file"),
+	"copy/big.bin":           []byte("a much larger file"),
+	"app/node_modules/c.log": []byte("log"),
+	"app/c.log":              []byte("log"),
+}
+
+func Test_Filters(t *testing.T) {
+	testCases := []struct {
+		name string
+		args models.ExecutionParams
+		want []string
+	}{
+		{name: "No filters", args: models.ExecutionParams{}, want: []string{
+			"a.jpg", "app/c.log", "app/node_modules/c.log", "b.txt", "big.bin", "copy/a.jpg", "copy/b.TXT", "copy/big.bin", "node_modules/a.jpg",
+		}},
+		{name: "Excluded directories are skipped", args: models.ExecutionParams{Exclude: []string{"node_modules"}}, want: []string{
+			"a.jpg", "b.txt", "big.bin", "copy/a.jpg", "copy/b.TXT", "copy/big.bin",
+		}},
+		{name: "Include pattern", args: models.ExecutionParams{Include: []string{"copy/*", "*.bin"}}, want: []string{
+			"big.bin", "copy/big.bin",
+		}},
+		{name: "Extensions are case-insensitive", args: models.ExecutionParams{Extensions: []string{"txt"}}, want: []string{
+			"b.txt", "copy/b.TXT",
+		}},
+		{name: "Excluded extensions", args: models.ExecutionParams{ExcludeExtensions: []string{".jpg", "log"}}, want: []string{
+			"b.txt", "big.bin", "copy/b.TXT", "copy/big.bin",
+		}},
+		{name: "Size bounds", args: models.ExecutionParams{MinSize: 4, MaxSize: 10}, want: []string{
+			"a.jpg", "b.txt", "copy/a.jpg", "copy/b.TXT", "node_modules/a.jpg",
+		}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := scanWithParams(t, filterTestFiles, tt.args)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_Filters_ModifiedRange(t *testing.T) {
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, filterTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{"a.jpg", "copy/a.jpg", "node_modules/a.jpg"} {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:    []string{dir},
+		ResultsDir:     outDir,
+		CacheDir:       outDir,
+		CPUs:           1,
+		BufSize:        1024,
+		ModifiedBefore: "2021-01-01",
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	want := []string{"a.jpg", "copy/a.jpg", "node_modules/a.jpg"}
+	if got := groupMembers(t, dir, app.GetResults()); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func Test_CLI_Scan_Filters(t *testing.T) {
+	tempDir, cleanup := createTestFilesByteArray(t, filterTestFiles)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+	outDir := t.TempDir()
+
+	code, out := runCLI(t, "scan", "-quiet", "-results-dir", outDir, "-cache-dir", outDir,
+		"-exclude", "node_modules", "-exclude", "copy", "-ext", "jpg,txt", tempDir)
+	if code != cli.ExitOK {
+		t.Fatalf("Expected no duplicates once the copies are excluded, got exit code %d: %s", code, out)
+	}
+
+	code, _ = runCLI(t, "scan", "-quiet", "-results-dir", outDir, "-cache-dir", outDir, "-exclude", "[", tempDir)
+	if code != cli.ExitError {
+		t.Errorf("Expected an invalid pattern to fail, got exit code %d", code)
+	}
+}
+
+var ignoreTestFiles = map[string][]byte{
+	".gitignore":              []byte("node_modules/\n*.log\n"),
+	"a.txt":                   []byte("shared"),
+	"node_modules/a.txt":      []byte("shared"),
+	"app/a.txt":               []byte("shared"),
+	"app/.dudeignore":         []byte("!*.log\ncache/\n"),
+	"app/x.log":               []byte("log line"),
+	"app/cache/a.txt":         []byte("shared"),
+	"y.log":                   []byte("log line"),
+	"z.log":                   []byte("log line"),
+	".git/objects/a.txt":      []byte("shared"),
+	"sub/.svn/pristine/a.txt": []byte("shared"),
+}
+
+func Test_Filters_IgnoreFiles(t *testing.T) {
+	testCases := []struct {
+		name string
+		args models.ExecutionParams
+		want []string
+	}{
+		{name: "VCS directories are skipped by default", args: models.ExecutionParams{}, want: []string{
+			"a.txt", "app/a.txt", "app/cache/a.txt", "app/x.log", "node_modules/a.txt", "y.log", "z.log",
+		}},
+		{name: "VCS directories can be scanned", args: models.ExecutionParams{ScanVCSDirs: true}, want: []string{
+			".git/objects/a.txt", "a.txt", "app/a.txt", "app/cache/a.txt", "app/x.log", "node_modules/a.txt", "sub/.svn/pristine/a.txt", "y.log", "z.log",
+		}},
+		{name: "Ignore files are honoured with nested precedence", args: models.ExecutionParams{RespectIgnoreFiles: true}, want: []string{
+			"a.txt", "app/a.txt",
+		}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := scanWithParams(t, ignoreTestFiles, tt.args)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
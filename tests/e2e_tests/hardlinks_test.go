@@ -0,0 +1,192 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/actions"
+	"DuDe/internal/cli"
+	"DuDe/internal/models"
+	process "DuDe/internal/processing"
+	"DuDe/internal/results"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var hardLinkTestFiles = map[string][]byte{
+	"a.txt":     []byte("linked content"),
+	"copy.txt":  []byte("linked content"),
+	"other.txt": []byte("other content"),
+}
+
+// linkTestFiles adds hard links to a.txt and other.txt below dir.
+func linkTestFiles(t *testing.T, dir string) {
+	t.Helper()
+	for target, link := range map[string]string{"a.txt": "sub/a-link.txt", "other.txt": "other-link.txt"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, link)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Link(filepath.Join(dir, target), filepath.Join(dir, link)); err != nil {
+			t.Skipf("hard links not supported here: %v", err)
+		}
+	}
+}
+
+func Test_HardLinks_AreNotDuplicates(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("hard links are only detected on Linux")
+	}
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, hardLinkTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+	linkTestFiles(t, dir)
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:     []string{dir},
+		ResultsDir:      outDir,
+		CacheDir:        outDir,
+		CPUs:            1,
+		BufSize:         1024,
+		ReportHardLinks: true,
+		ResultFormats:   []string{"json", "html"},
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	// a.txt and its link are one file, duplicated by copy.txt; other.txt and its link are no duplicate at all.
+	groups := app.GetResults()
+	if got, want := groupMembers(t, dir, groups), []string{"a.txt", "copy.txt"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	original := groups[0]
+	if len(original.HardLinks) != 1 || !strings.HasSuffix(original.HardLinks[0], "a-link.txt") {
+		t.Errorf("Expected the link to be attached to a.txt, got %+v", original)
+	}
+	if summary := app.GetSummary(); summary.FilesScanned != 3 || summary.ReclaimableBytes != int64(len("linked content")) {
+		t.Errorf("Expected links to be counted once, got %+v", summary)
+	}
+
+	sets := app.GetHardLinks()
+	if len(sets) != 2 {
+		t.Fatalf("Expected 2 hard-link sets, got %+v", sets)
+	}
+
+	written, err := filepath.Glob(filepath.Join(outDir, "results_*.json"))
+	if err != nil || len(written) != 1 {
+		t.Fatalf("Expected a JSON results file, got %v (%v)", written, err)
+	}
+	data, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report results.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.HardLinks) != 2 || len(report.Groups[0].Files[0].HardLinks) != 1 {
+		t.Errorf("Expected the hard-link sets in the report, got %+v", report)
+	}
+
+	page, err := os.ReadFile(strings.TrimSuffix(written[0], ".json") + ".html")
+	if err != nil || !strings.Contains(string(page), "Hard-link sets") || !strings.Contains(string(page), "other-link.txt") {
+		t.Errorf("Expected the hard-link sets in the HTML report (%v)", err)
+	}
+}
+
+func Test_CLI_Scan_HardLinks(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("hard links are only detected on Linux")
+	}
+	dir, cleanup := createTestFilesByteArray(t, map[string][]byte{"a.txt": []byte("only one file")})
+	defer func() { cleanup(); deleteTestFolder(t) }()
+	if err := os.Link(filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")); err != nil {
+		t.Skipf("hard links not supported here: %v", err)
+	}
+	outDir := t.TempDir()
+
+	code, out := runCLI(t, "scan", "-quiet", "-hard-links", "-results-dir", outDir, "-cache-dir", outDir, dir)
+	if code != cli.ExitOK {
+		t.Fatalf("Expected no duplicates, got exit code %d", code)
+	}
+	if !strings.Contains(out, "1 files have several hard links") || !strings.Contains(out, "= "+filepath.Join(dir, "b.txt")) {
+		t.Errorf("Expected the hard-link set to be listed, got:\n%s", out)
+	}
+}
+
+// scanLinkedDuplicate scans x.bin and its duplicate y.bin, with link created as a hard link
+// to y.bin, below dir if it is relative.
+func scanLinkedDuplicate(t *testing.T, link string) (*process.FrontendApp, string) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("hard links are only detected on Linux")
+	}
+	content := bytes.Repeat([]byte("x"), 50000)
+	dir, cleanup := createTestFilesByteArray(t, map[string][]byte{"x.bin": content, "y.bin": content})
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+	if !filepath.IsAbs(link) {
+		link = filepath.Join(dir, link)
+	}
+	if err := os.Link(filepath.Join(dir, "y.bin"), link); err != nil {
+		t.Skipf("hard links not supported here: %v", err)
+	}
+
+	app := setupTestApp(t)
+	outDir := t.TempDir()
+	args := models.ExecutionParams{Directories: []string{dir}, ResultsDir: outDir, CacheDir: outDir, CPUs: 1, BufSize: 1024}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	groups := app.GetResults()
+	if len(groups) != 1 || len(groups[0].DuplicatesFound) != 1 || groups[0].FilePath != filepath.Join(dir, "x.bin") {
+		t.Fatalf("Expected x.bin duplicated by y.bin, got %+v", groups)
+	}
+	return app, dir
+}
+
+func Test_HardLinks_ActionsRemoveEveryLink(t *testing.T) {
+	app, dir := scanLinkedDuplicate(t, "z.bin")
+	if summary := app.GetSummary(); summary.ReclaimableBytes != 50000 {
+		t.Errorf("Expected 50000 reclaimable bytes, got %+v", summary)
+	}
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Delete})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 || report.FilesFailed != 0 || report.BytesFreed != 50000 {
+		t.Errorf("Expected both links deleted reclaiming 50000 bytes, got %+v", report)
+	}
+	if left := remaining(dir, "x.bin", "y.bin", "z.bin"); !slices.Equal(left, []string{"x.bin"}) {
+		t.Errorf("Expected only x.bin to remain, got %v", left)
+	}
+	if groups := app.GetResults(); len(groups) != 0 {
+		t.Errorf("Expected the group to be pruned, got %+v", groups)
+	}
+}
+
+func Test_HardLinks_LinkOutsideTheScanKeepsTheData(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "kept.bin")
+	app, dir := scanLinkedDuplicate(t, outside)
+	if summary := app.GetSummary(); summary.DuplicateFiles != 1 || summary.ReclaimableBytes != 0 {
+		t.Errorf("Expected 1 duplicate reclaiming nothing, got %+v", summary)
+	}
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Delete})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 1 || report.BytesFreed != 0 {
+		t.Errorf("Expected y.bin deleted reclaiming nothing, got %+v", report)
+	}
+	if left := remaining(dir, "x.bin", "y.bin"); !slices.Equal(left, []string{"x.bin"}) {
+		t.Errorf("Expected only x.bin to remain, got %v", left)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("The link outside the scan must be kept: %v", err)
+	}
+}
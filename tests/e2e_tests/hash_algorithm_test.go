@@ -0,0 +1,129 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/common/hashing"
+	database "DuDe/internal/db"
+	"DuDe/internal/models"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test_HashAlgorithm_FindsDuplicates verifies every supported algorithm detects the same duplicates.
+func Test_HashAlgorithm_FindsDuplicates(t *testing.T) {
+	for _, algorithm := range hashing.Algorithms() {
+		t.Run(algorithm, func(t *testing.T) {
+			app := setupTestApp(t)
+
+			files := map[string][]byte{
+				"file1.txt":     []byte("duplicate content"),
+				"sub/file2.txt": []byte("duplicate content"),
+				"file3.txt":     []byte("unique content"),
+			}
+			tempDir, cleanup := createTestFilesByteArray(t, files)
+			defer func() { cleanup(); deleteTestFolder(t) }()
+
+			outDir := t.TempDir()
+			args := models.ExecutionParams{
+				Directories:   []string{tempDir},
+				ResultsDir:    outDir,
+				CacheDir:      outDir,
+				UseCache:      true,
+				CPUs:          1,
+				BufSize:       1024,
+				HashAlgorithm: algorithm,
+			}
+
+			if err := app.StartExecution(args); err != nil {
+				t.Fatalf("E2E app failed with error: %v", err)
+			}
+
+			results := app.GetResults()
+			if len(results) != 1 || len(results[0].DuplicatesFound) != 1 {
+				t.Fatalf("Expected 1 group with 1 duplicate, got %+v", results)
+			}
+			if results[0].HashAlgorithm != algorithm {
+				t.Errorf("Expected hash algorithm %s, got %s", algorithm, results[0].HashAlgorithm)
+			}
+		})
+	}
+}
+
+// Test_HashAlgorithm_CacheIsNotMixed verifies a cache built with one algorithm is re-hashed
+// (and overwritten) when a run uses a different algorithm.
+func Test_HashAlgorithm_CacheIsNotMixed(t *testing.T) {
+	files := map[string][]byte{
+		"file1.txt": []byte("duplicate content"),
+		"file2.txt": []byte("duplicate content"),
+	}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories: []string{tempDir},
+		ResultsDir:  outDir,
+		CacheDir:    outDir,
+		UseCache:    true,
+		CPUs:        1,
+		BufSize:     1024,
+	}
+
+	for _, algorithm := range []string{hashing.MD5, hashing.SHA256} {
+		args.HashAlgorithm = algorithm
+		if err := setupTestApp(t).StartExecution(args); err != nil {
+			t.Fatalf("E2E app failed with error: %v", err)
+		}
+	}
+
+	db, err := database.InitializeDatabase(outDir)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	defer db.Close()
+
+	records, err := database.NewFileHashRepository(db).GetAll()
+	if err != nil {
+		t.Fatalf("failed to read cache: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 cached files, got %d", len(records))
+	}
+	for _, r := range records {
+		if r.HashAlgorithm != hashing.SHA256 {
+			t.Errorf("Expected cached hash of %s to use %s, got %s", r.FilePath, hashing.SHA256, r.HashAlgorithm)
+		}
+		if len(r.Hash) != 64 {
+			t.Errorf("Expected a SHA-256 hex digest for %s, got %q", r.FilePath, r.Hash)
+		}
+	}
+}
+
+func Test_Cache_UnusableCacheIsAWarning(t *testing.T) {
+	files := map[string][]byte{
+		"file1.txt": []byte("duplicate content"),
+		"file2.txt": []byte("duplicate content"),
+	}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories: []string{tempDir},
+		ResultsDir:  outDir,
+		CacheDir:    filepath.Join(outDir, "missing"),
+		UseCache:    true,
+		CPUs:        1,
+		BufSize:     1024,
+	}
+	app := setupTestApp(t)
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("Expected the scan to run without its cache, got: %v", err)
+	}
+	if len(app.GetResults()) != 1 {
+		t.Errorf("Expected 1 duplicate group, got %+v", app.GetResults())
+	}
+	if warnings := app.GetWarnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "hash cache disabled") {
+		t.Errorf("Expected a warning about the cache, got %v", warnings)
+	}
+}
@@ -0,0 +1,253 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/actions"
+	"DuDe/internal/cli"
+	"DuDe/internal/common"
+	"DuDe/internal/models"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// assertRestored checks that every file of actionTestFiles is back, as an independent regular file.
+func assertRestored(t *testing.T, dir string) {
+	t.Helper()
+	for name, content := range actionTestFiles {
+		path := filepath.Join(dir, name)
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Errorf("Expected %s to be restored: %v", name, err)
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			t.Errorf("Expected %s to be a regular file, got %v", name, info.Mode())
+		}
+		if got, _ := os.ReadFile(path); string(got) != string(content) {
+			t.Errorf("Restored %s has content %q", name, got)
+		}
+	}
+}
+
+func Test_Journal_RestoresEveryAction(t *testing.T) {
+	for _, action := range []string{actions.Delete, actions.Quarantine, actions.Trash, actions.Link, actions.Symlink} {
+		t.Run(action, func(t *testing.T) {
+			if action == actions.Trash && runtime.GOOS != "linux" {
+				t.Skip("trash is only supported on Linux")
+			}
+			t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+			app, dir := scanForActions(t, actionTestFiles)
+			group := app.GetResults()[0]
+
+			applied, err := app.ApplyAction(actions.Request{Action: action, QuarantineDir: t.TempDir()})
+			if err != nil {
+				t.Fatalf("ApplyAction failed: %v", err)
+			}
+			if applied.FilesActed != 2 || applied.Session == "" {
+				t.Fatalf("Expected 2 journaled files, got %+v", applied)
+			}
+
+			// Each file is journaled before and after it is acted on.
+			entries, err := actions.ReadJournal(app.Args.CacheDir)
+			if err != nil || len(entries) != 4 {
+				t.Fatalf("Expected 4 journal entries, got %d (%v)", len(entries), err)
+			}
+			for i, e := range entries {
+				if e.Action != action || e.Original != group.FilePath || e.Hash != group.Hash || e.Session != applied.Session || e.Pending != (i%2 == 0) {
+					t.Errorf("Unexpected journal entry %+v", e)
+				}
+			}
+			if sessions, err := actions.Sessions(app.Args.CacheDir); err != nil || len(sessions) != 1 || sessions[0].Files != 2 {
+				t.Errorf("Expected one session of 2 files, got %+v (%v)", sessions, err)
+			}
+
+			restored, err := app.RestoreFromJournal(actions.RestoreRequest{})
+			if err != nil {
+				t.Fatalf("RestoreFromJournal failed: %v", err)
+			}
+			if restored.FilesActed != 2 || restored.FilesFailed != 0 {
+				t.Fatalf("Expected 2 restored files, got %+v", restored)
+			}
+			assertRestored(t, dir)
+
+			// Restoring again must not touch anything.
+			again, err := app.RestoreFromJournal(actions.RestoreRequest{Session: applied.Session})
+			if err != nil || again.FilesActed != 0 || again.FilesFailed != 0 {
+				t.Errorf("Expected a repeated restore to be a no-op, got %+v (%v)", again, err)
+			}
+		})
+	}
+}
+
+func Test_Journal_RestoreSingleGroup(t *testing.T) {
+	files := map[string][]byte{
+		"a1.txt": []byte("group a"),
+		"a2.txt": []byte("group a"),
+		"b1.txt": []byte("group b"),
+		"b2.txt": []byte("group b"),
+	}
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, files)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories: []string{dir},
+		ResultsDir:  outDir,
+		CacheDir:    outDir,
+		CPUs:        1,
+		BufSize:     1024,
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	groups := app.GetResults()
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %+v", groups)
+	}
+
+	if _, err := app.ApplyAction(actions.Request{Action: actions.Delete}); err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+
+	report, err := app.RestoreFromJournal(actions.RestoreRequest{Group: groups[0].FilePath})
+	if err != nil {
+		t.Fatalf("RestoreFromJournal failed: %v", err)
+	}
+	if report.FilesActed != 1 {
+		t.Fatalf("Expected 1 restored file, got %+v", report)
+	}
+	if _, err := os.Stat(groups[0].DuplicatesFound[0].FilePath); err != nil {
+		t.Errorf("Expected the duplicate of the selected group to be restored: %v", err)
+	}
+	if _, err := os.Stat(groups[1].DuplicatesFound[0].FilePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the duplicate of the other group to stay deleted")
+	}
+
+	if _, err := app.RestoreFromJournal(actions.RestoreRequest{Group: filepath.Join(dir, "nope")}); !errors.Is(err, actions.ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound, got %v", err)
+	}
+	if _, err := app.RestoreFromJournal(actions.RestoreRequest{Session: "nope"}); !errors.Is(err, actions.ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func Test_Journal_RestoreRefusesToOverwrite(t *testing.T) {
+	app, _ := scanForActions(t, actionTestFiles)
+
+	applied, err := app.ApplyAction(actions.Request{Action: actions.Delete})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	recreated := applied.Outcomes[0].Path
+	if err := os.WriteFile(recreated, []byte("new file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := app.RestoreFromJournal(actions.RestoreRequest{})
+	if err != nil {
+		t.Fatalf("RestoreFromJournal failed: %v", err)
+	}
+	if report.FilesActed != 1 || report.FilesFailed != 1 {
+		t.Errorf("Expected 1 restored and 1 refused file, got %+v", report)
+	}
+	if got, _ := os.ReadFile(recreated); string(got) != "new file" {
+		t.Errorf("A file created after the action must not be overwritten, got %q", got)
+	}
+}
+
+func Test_CLI_Restore(t *testing.T) {
+	tempDir, cleanup := createTestFilesByteArray(t, actionTestFiles)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+	outDir := t.TempDir()
+
+	code, _ := runCLI(t, "scan", "-quiet", "-results-dir", outDir, "-cache-dir", outDir, "-action", "delete", tempDir)
+	if code != cli.ExitDuplicates {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitDuplicates, code)
+	}
+	if left := remaining(tempDir, "a.txt", "sub/b.txt", "sub/c.txt"); len(left) != 1 {
+		t.Fatalf("Expected one copy to remain, got %v", left)
+	}
+
+	code, _ = runCLI(t, "restore", "-cache-dir", outDir)
+	if code != cli.ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", cli.ExitOK, code)
+	}
+	assertRestored(t, tempDir)
+
+	code, _ = runCLI(t, "restore", "-cache-dir", t.TempDir())
+	if code != cli.ExitError {
+		t.Errorf("Expected exit code %d without a journal, got %d", cli.ExitError, code)
+	}
+}
+
+// Test_Journal_RestoresInterruptedActions simulates a crash between acting on a file and
+// journaling it: only the pending entries are left, yet restoring brings every file back.
+func Test_Journal_RestoresInterruptedActions(t *testing.T) {
+	for _, action := range []string{actions.Delete, actions.Quarantine, actions.Trash, actions.Link, actions.Symlink} {
+		t.Run(action, func(t *testing.T) {
+			if action == actions.Trash && runtime.GOOS != "linux" {
+				t.Skip("trash is only supported on Linux")
+			}
+			t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+			app, dir := scanForActions(t, actionTestFiles)
+			applied, err := app.ApplyAction(actions.Request{Action: action, QuarantineDir: t.TempDir()})
+			if err != nil || applied.FilesActed != 2 {
+				t.Fatalf("Expected 2 files acted on, got %+v (%v)", applied, err)
+			}
+
+			entries, err := actions.ReadJournal(app.Args.CacheDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var pending []actions.JournalEntry
+			for _, e := range entries {
+				if e.Pending {
+					pending = append(pending, e)
+				}
+			}
+			// The crash hit before the last file was touched.
+			untouched := pending[0]
+			untouched.Path = filepath.Join(dir, "u.txt")
+			writeJournal(t, app.Args.CacheDir, append(pending, untouched)...)
+
+			restored, err := app.RestoreFromJournal(actions.RestoreRequest{})
+			if err != nil {
+				t.Fatalf("RestoreFromJournal failed: %v", err)
+			}
+			if restored.FilesActed != 2 || restored.FilesFailed != 0 {
+				t.Fatalf("Expected 2 restored files and the untouched one left alone, got %+v", restored)
+			}
+			assertRestored(t, dir)
+			if got, _ := os.ReadFile(untouched.Path); string(got) != "unique content" {
+				t.Errorf("Expected the untouched file to be left alone, got %q", got)
+			}
+
+			again, err := app.RestoreFromJournal(actions.RestoreRequest{})
+			if err != nil || again.FilesActed != 0 || again.FilesFailed != 0 {
+				t.Errorf("Expected a repeated restore to be a no-op, got %+v (%v)", again, err)
+			}
+		})
+	}
+}
+
+// writeJournal replaces the journal in dir with entries.
+func writeJournal(t *testing.T, dir string, entries ...actions.JournalEntry) {
+	t.Helper()
+	var lines []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	if err := os.WriteFile(filepath.Join(dir, common.JournalFilename), lines, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
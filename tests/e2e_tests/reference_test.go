@@ -0,0 +1,130 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/actions"
+	"DuDe/internal/cli"
+	"DuDe/internal/models"
+	process "DuDe/internal/processing"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var referenceTestFiles = map[string][]byte{
+	"archive/a.jpg":      []byte("picture a"),
+	"archive/copy/a.jpg": []byte("picture a"),
+	"archive/b.jpg":      []byte("picture b"),
+	"inbox/a.jpg":        []byte("picture a"),
+	"inbox/sub/a.jpg":    []byte("picture a"),
+	"inbox/c.jpg":        []byte("picture c"),
+	"inbox/c2.jpg":       []byte("picture c"),
+}
+
+// scanAgainstReference scans inbox against the archive reference of referenceTestFiles.
+func scanAgainstReference(t *testing.T, keepRules ...string) (*process.FrontendApp, string) {
+	t.Helper()
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, referenceTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:   []string{filepath.Join(dir, "inbox")},
+		ReferenceDirs: []string{filepath.Join(dir, "archive")},
+		ResultsDir:    outDir,
+		CacheDir:      outDir,
+		CPUs:          1,
+		BufSize:       1024,
+		KeepRules:     keepRules,
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	return app, dir
+}
+
+func Test_Reference_OnlyCopiesOfReferenceFiles(t *testing.T) {
+	// The rule would prefer inbox/a.jpg, but only a reference file can be the original.
+	app, dir := scanAgainstReference(t, "shortest-path")
+
+	// The copies within the inbox and within the archive are not reported.
+	groups := app.GetResults()
+	if got, want := groupMembers(t, dir, groups), []string{"archive/a.jpg", "inbox/a.jpg", "inbox/sub/a.jpg"}; !slices.Equal(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	if got := relPaths(t, dir, groups[0].FilePath); got[0] != "archive/a.jpg" {
+		t.Errorf("Expected the reference file to be the original, got %v", got)
+	}
+	if summary := app.GetSummary(); summary.DuplicateFiles != 2 || summary.FilesScanned != 7 {
+		t.Errorf("Expected the two inbox copies out of every scanned file, got %+v", summary)
+	}
+}
+
+func Test_Reference_ActionsLeaveReferenceIntact(t *testing.T) {
+	app, dir := scanAgainstReference(t)
+
+	report, err := app.ApplyAction(actions.Request{Action: actions.Delete})
+	if err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if report.FilesActed != 2 || report.FilesFailed != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	want := []string{"archive/a.jpg", "archive/copy/a.jpg", "archive/b.jpg", "inbox/c.jpg", "inbox/c2.jpg"}
+	if left := remaining(dir, append(want, "inbox/a.jpg", "inbox/sub/a.jpg")...); !slices.Equal(left, want) {
+		t.Errorf("Expected %v to remain, got %v", want, left)
+	}
+
+	// Groups from elsewhere still never lose a reference file.
+	group := models.FileHash{FilePath: filepath.Join(dir, "inbox/c.jpg"),
+		DuplicatesFound: []models.FileHash{{FilePath: filepath.Join(dir, "archive/b.jpg")}}}
+	report, err = actions.Apply(context.Background(), []models.FileHash{group},
+		actions.Request{Action: actions.Delete, ReferenceDirs: []string{filepath.Join(dir, "archive")}})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if report.FilesFailed != 1 || !strings.Contains(report.Outcomes[0].Error, actions.ErrReferenceFile.Error()) {
+		t.Errorf("Expected the reference file to be refused, got %+v", report.Outcomes)
+	}
+	if left := remaining(dir, "archive/b.jpg"); len(left) != 1 {
+		t.Errorf("Expected the reference file to remain")
+	}
+}
+
+func Test_Reference_InsideReferenceIsRejected(t *testing.T) {
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, referenceTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:   []string{filepath.Join(dir, "archive", "copy")},
+		ReferenceDirs: []string{filepath.Join(dir, "archive")},
+		ResultsDir:    outDir,
+		CacheDir:      outDir,
+	}
+	if err := app.StartExecution(args); err == nil || !strings.Contains(err.Error(), "reference") {
+		t.Errorf("Expected a directory inside the reference to be rejected, got %v", err)
+	}
+}
+
+func Test_CLI_Scan_Reference(t *testing.T) {
+	dir, cleanup := createTestFilesByteArray(t, referenceTestFiles)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+	outDir := t.TempDir()
+
+	code, out := runCLI(t, "scan", "-quiet", "-reference", filepath.Join(dir, "archive"), "-action", "delete",
+		"-results-dir", outDir, "-cache-dir", outDir, filepath.Join(dir, "inbox"))
+	if code != cli.ExitDuplicates {
+		t.Fatalf("Unexpected exit code %d: %s", code, out)
+	}
+	if !strings.Contains(out, "Found 1 duplicate groups") {
+		t.Errorf("Expected one group, got:\n%s", out)
+	}
+	want := []string{"archive/a.jpg", "archive/copy/a.jpg", "inbox/c.jpg", "inbox/c2.jpg"}
+	if left := remaining(dir, append(want, "inbox/a.jpg", "inbox/sub/a.jpg")...); !slices.Equal(left, want) {
+		t.Errorf("Expected %v to remain, got %v", want, left)
+	}
+}
@@ -0,0 +1,374 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/actions"
+	"DuDe/internal/common"
+	"DuDe/internal/models"
+	"DuDe/internal/results"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var formatTestFiles = map[string][]byte{
+	"a1.txt":     []byte("group a"),
+	"sub/a2.txt": []byte("group a"),
+	"b1.txt":     []byte("group b!"),
+	"b2.txt":     []byte("group b!"),
+	"b3.txt":     []byte("group b!"),
+	"c.txt":      []byte("unique"),
+}
+
+// scanWithFormats runs an execution writing the given formats and returns the files written.
+func scanWithFormats(t *testing.T, formats ...string) []string {
+	t.Helper()
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, formatTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:   []string{dir},
+		ResultsDir:    outDir,
+		CacheDir:      outDir,
+		CPUs:          1,
+		BufSize:       1024,
+		ResultFormats: formats,
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	written, err := filepath.Glob(filepath.Join(outDir, "results_*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return written
+}
+
+func checkGroups(t *testing.T, groups []results.Group) {
+	t.Helper()
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+	members := map[int]bool{}
+	for _, g := range groups {
+		members[len(g.Files)] = true
+		if g.Hash == "" || g.ID == "" || !strings.HasPrefix(g.Hash, g.ID) || g.HashAlgorithm != "md5" {
+			t.Errorf("Group is missing its identity: %+v", g)
+		}
+		for i, f := range g.Files {
+			wantRole := results.RoleDuplicate
+			if i == 0 {
+				wantRole = results.RoleOriginal
+			}
+			if f.Role != wantRole || f.Size != g.Size || f.ModTime == "" || f.Path == "" {
+				t.Errorf("Unexpected member %+v of group %s", f, g.ID)
+			}
+		}
+	}
+	if !members[2] || !members[3] {
+		t.Errorf("Expected groups of 2 and 3 files, got %+v", groups)
+	}
+}
+
+// checkSummary checks the summary of a scan of formatTestFiles.
+func checkSummary(t *testing.T, s results.ScanSummary) {
+	t.Helper()
+	// Group b wastes 2 × 8 bytes, group a 1 × 7 bytes.
+	want := results.ScanSummary{FilesScanned: 6, BytesScanned: 44, DuplicateGroups: 2, DuplicateFiles: 3, ReclaimableBytes: 23}
+	if s.FilesScanned != want.FilesScanned || s.BytesScanned != want.BytesScanned || s.DuplicateGroups != want.DuplicateGroups ||
+		s.DuplicateFiles != want.DuplicateFiles || s.ReclaimableBytes != want.ReclaimableBytes {
+		t.Errorf("Expected totals %+v, got %+v", want, s)
+	}
+	if len(s.LargestGroups) != 2 || s.LargestGroups[0].ReclaimableBytes != 16 || s.LargestGroups[0].Files != 3 {
+		t.Errorf("Expected group b first in the largest groups, got %+v", s.LargestGroups)
+	}
+	if len(s.ByExtension) != 1 || s.ByExtension[0] != (results.Breakdown{Key: ".txt", DuplicateFiles: 3, ReclaimableBytes: 23}) {
+		t.Errorf("Unexpected extension breakdown %+v", s.ByExtension)
+	}
+	var total int64
+	for _, b := range s.ByDirectory {
+		total += b.ReclaimableBytes
+	}
+	if total != s.ReclaimableBytes {
+		t.Errorf("Expected the directory breakdown to add up to %d, got %+v", s.ReclaimableBytes, s.ByDirectory)
+	}
+}
+
+func Test_Results_JSON(t *testing.T) {
+	written := scanWithFormats(t, "json")
+	if len(written) != 1 || filepath.Ext(written[0]) != ".json" {
+		t.Fatalf("Expected a single JSON file, got %v", written)
+	}
+
+	data, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report results.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if report.Version == 0 || report.GeneratedAt == "" {
+		t.Errorf("Expected version and generation time, got %+v", report)
+	}
+	checkGroups(t, report.Groups)
+	checkSummary(t, report.Summary)
+}
+
+func Test_Results_NDJSON(t *testing.T) {
+	written := scanWithFormats(t, "ndjson")
+	if len(written) != 1 || filepath.Ext(written[0]) != ".ndjson" {
+		t.Fatalf("Expected a single NDJSON file, got %v", written)
+	}
+
+	f, err := os.Open(written[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var groups []results.Group
+	var summaries []results.ScanSummary
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line struct{ Type string }
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Line %q is not JSON: %v", scanner.Text(), err)
+		}
+		switch line.Type {
+		case results.LineGroup:
+			if len(summaries) > 0 {
+				t.Errorf("Expected the summary after every group")
+			}
+			var g results.Group
+			if err := json.Unmarshal(scanner.Bytes(), &g); err != nil {
+				t.Fatalf("Line %q is not a JSON group: %v", scanner.Text(), err)
+			}
+			groups = append(groups, g)
+		case results.LineSummary:
+			var s results.ScanSummary
+			if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+				t.Fatalf("Line %q is not a JSON summary: %v", scanner.Text(), err)
+			}
+			summaries = append(summaries, s)
+		default:
+			t.Errorf("Unexpected line type %q", line.Type)
+		}
+	}
+	checkGroups(t, groups)
+	if len(summaries) != 1 {
+		t.Fatalf("Expected one summary line, got %d", len(summaries))
+	}
+	checkSummary(t, summaries[0])
+}
+
+func Test_Results_MultipleFormatsShareOneName(t *testing.T) {
+	written := scanWithFormats(t, "CSV", "json", "csv")
+	if len(written) != 3 {
+		t.Fatalf("Expected one CSV with its summary and one JSON file, got %v", written)
+	}
+	base := func(p string) string { return strings.TrimSuffix(strings.TrimSuffix(p, filepath.Ext(p)), "_summary") }
+	if base(written[0]) != base(written[1]) || base(written[1]) != base(written[2]) {
+		t.Errorf("Expected all formats to share a name, got %v", written)
+	}
+}
+
+func Test_Results_HTML(t *testing.T) {
+	written := scanWithFormats(t, "html")
+	if len(written) != 1 || filepath.Ext(written[0]) != ".html" {
+		t.Fatalf("Expected a single HTML file, got %v", written)
+	}
+
+	data, err := os.ReadFile(written[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+
+	for name := range formatTestFiles {
+		if name == "c.txt" {
+			if strings.Contains(page, "c.txt") {
+				t.Errorf("Unique file c.txt must not be reported")
+			}
+			continue
+		}
+		if !strings.Contains(page, filepath.Base(name)) {
+			t.Errorf("Expected %s in the report", name)
+		}
+	}
+	if !strings.Contains(page, "files scanned") || !strings.Contains(page, "<h2>Extensions</h2>") || !strings.Contains(page, "<h2>Top-level directories</h2>") {
+		t.Errorf("Expected the scan summary in the report")
+	}
+	if strings.Count(page, "<details>") != 2 {
+		t.Errorf("Expected 2 collapsible groups")
+	}
+	// Group b wastes 2 × 8 bytes, group a 1 × 7 bytes.
+	if strings.Index(page, "b1.txt") > strings.Index(page, "a1.txt") {
+		t.Errorf("Expected groups to be sorted by wasted bytes")
+	}
+	if strings.Contains(page, "http://") || strings.Contains(page, "https://") || strings.Contains(page, "<script") {
+		t.Errorf("Expected a self-contained report without external resources or scripts")
+	}
+}
+
+func Test_Results_HTMLEscapesPaths(t *testing.T) {
+	report := results.NewReport([]models.FileHash{{
+		FileName: "<b>x.txt", FilePath: "/tmp/<b>x.txt", Hash: "abc", FileSize: 3,
+		DuplicatesFound: []models.FileHash{{FileName: "y.txt", FilePath: "/tmp/y.txt", FileSize: 3}},
+	}}, results.ScanSummary{})
+	w, err := results.Get("html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page strings.Builder
+	if err := w.Write(&page, report, results.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(page.String(), "<b>x.txt") || !strings.Contains(page.String(), "&lt;b&gt;x.txt") {
+		t.Errorf("Expected file names to be escaped")
+	}
+}
+
+func Test_Results_ExportAfterAction(t *testing.T) {
+	app, _ := scanForActions(t, actionTestFiles)
+	if _, err := app.ApplyAction(actions.Request{Action: actions.Delete}); err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	if _, err := app.ExportResults([]string{"html"}); !errors.Is(err, actions.ErrNoResults) {
+		t.Errorf("Expected ErrNoResults once every duplicate is gone, got %v", err)
+	}
+
+	app, _ = scanForActions(t, actionTestFiles)
+	written, err := app.ExportResults([]string{"json", "html"})
+	if err != nil || len(written) != 2 {
+		t.Fatalf("Expected 2 exported files, got %v (%v)", written, err)
+	}
+	if _, err := app.ExportResults([]string{"xml"}); !errors.Is(err, results.ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	for n, want := range map[int64]string{0: "0 B", 1023: "1023 B", 1024: "1.0 KiB", 1536: "1.5 KiB", 5 << 30: "5.0 GiB"} {
+		if got := results.HumanBytes(n); got != want {
+			t.Errorf("HumanBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func Test_Results_CSVFileLayout(t *testing.T) {
+	written := scanWithFormats(t, "csv")
+	if len(written) != 2 {
+		t.Fatalf("Expected a CSV file and its summary, got %v", written)
+	}
+	lines, err := readResultsFile(t, filepath.Dir(written[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := lines[0]
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	if !slices.Equal(header, common.ResultsHeaderV2) {
+		t.Fatalf("Unexpected header %v", header)
+	}
+
+	roles := map[string][]string{}
+	for _, line := range lines[1:] {
+		groupID, role, size, modTime, hash := line[0], line[1], line[4], line[5], line[6]
+		if !strings.HasPrefix(hash, groupID) || size == "" || modTime == "" || line[7] != "md5" {
+			t.Errorf("Incomplete row %v", line)
+		}
+		roles[groupID] = append(roles[groupID], role)
+	}
+	if len(roles) != 2 {
+		t.Fatalf("Expected 2 groups, got %v", roles)
+	}
+	for id, r := range roles {
+		if r[0] != results.RoleOriginal || slices.Contains(r[1:], results.RoleOriginal) {
+			t.Errorf("Expected exactly one leading original in group %s, got %v", id, r)
+		}
+	}
+}
+
+func Test_Results_CSVOptions(t *testing.T) {
+	report := results.NewReport([]models.FileHash{{
+		FileName: "a.txt", FilePath: "/tmp/a.txt", Hash: "abc", FileSize: 3,
+		DuplicatesFound: []models.FileHash{{FileName: "b.txt", FilePath: "/tmp/b.txt", FileSize: 3}},
+	}}, results.ScanSummary{})
+	w, err := results.Get("csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := w.Write(&out, report, results.Options{CSVDelimiter: ';', CSVOmitBOM: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "Group ID;Role;") {
+		t.Errorf("Expected no BOM and a semicolon delimiter, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := w.Write(&out, report, results.Options{CSVLayout: results.CSVLayoutPairs}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeffFile Name,Path,Duplicate File Name,Duplicate Path\na.txt,/tmp/a.txt,b.txt,/tmp/b.txt\n------,------,------,------\n"
+	if out.String() != want {
+		t.Errorf("Expected the legacy layout\n%q\ngot\n%q", want, out.String())
+	}
+}
+
+func Test_Results_CSVSummary(t *testing.T) {
+	written := scanWithFormats(t, "csv")
+	var summaryFile string
+	for _, p := range written {
+		if strings.HasSuffix(p, "_summary.csv") {
+			summaryFile = p
+		}
+	}
+	if summaryFile == "" {
+		t.Fatalf("Expected a summary next to the results, got %v", written)
+	}
+
+	data, err := os.ReadFile(summaryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := strings.TrimPrefix(string(data), "\ufeff")
+	for _, row := range []string{
+		strings.Join(common.SummaryHeader, ",") + "\n",
+		"total,scanned,6,44\n",
+		"total,reclaimable,3,23\n",
+		"extension,.txt,3,23\n",
+	} {
+		if !strings.Contains(summary, row) {
+			t.Errorf("Expected row %q in the summary\n%s", row, summary)
+		}
+	}
+	if strings.Count(summary, "largest group,") != 2 {
+		t.Errorf("Expected both groups among the largest\n%s", summary)
+	}
+}
+
+func Test_Results_SummaryAfterAction(t *testing.T) {
+	app, _ := scanForActions(t, actionTestFiles)
+	before := app.GetSummary()
+	if before == nil || before.DuplicateFiles == 0 || before.ReclaimableBytes == 0 {
+		t.Fatalf("Expected a summary with duplicates, got %+v", before)
+	}
+
+	if _, err := app.ApplyAction(actions.Request{Action: actions.Delete}); err != nil {
+		t.Fatalf("ApplyAction failed: %v", err)
+	}
+	after := app.GetSummary()
+	if after == nil || after.DuplicateFiles != 0 || after.ReclaimableBytes != 0 || after.FilesScanned != before.FilesScanned {
+		t.Errorf("Expected nothing left to reclaim and the scanned totals kept, got %+v", after)
+	}
+}
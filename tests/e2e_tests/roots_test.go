@@ -0,0 +1,86 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var rootTestFiles = map[string][]byte{
+	"a.txt":        []byte("shared"),
+	"photos/a.txt": []byte("shared"),
+	"photos/b.txt": []byte("unique"),
+}
+
+func Test_Roots_NestedRootIsWalkedOnce(t *testing.T) {
+	app := setupTestApp(t)
+	dir, cleanup := createTestFilesByteArray(t, rootTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories: []string{filepath.Join(dir, "photos"), dir, dir + string(filepath.Separator)},
+		ResultsDir:  outDir,
+		CacheDir:    outDir,
+		CPUs:        1,
+		BufSize:     1024,
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	if got := app.Args.Directories; !slices.Equal(got, []string{dir}) {
+		t.Errorf("Expected the nested roots to be collapsed into %s, got %v", dir, got)
+	}
+	if got, want := groupMembers(t, dir, app.GetResults()), []string{"a.txt", "photos/a.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if summary := app.GetSummary(); summary.FilesScanned != 3 {
+		t.Errorf("Expected every file to be scanned once, got %+v", summary)
+	}
+}
+
+func Test_Roots_OwnDirectoriesAreNotScanned(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		cacheDir string
+	}{
+		{name: "Inside the scan root", cacheDir: "dude"},
+		{name: "The scan root itself", cacheDir: "."},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, cleanup := createTestFilesByteArray(t, rootTestFiles)
+			t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+			args := models.ExecutionParams{
+				Directories: []string{dir},
+				ResultsDir:  filepath.Join(dir, "results"),
+				CacheDir:    filepath.Join(dir, tt.cacheDir),
+				UseCache:    true,
+				CPUs:        1,
+				BufSize:     1024,
+			}
+
+			for _, own := range []string{args.ResultsDir, args.CacheDir} {
+				if err := os.MkdirAll(own, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The second scan finds the cache and results of the first one in the tree.
+			for run := 1; run <= 2; run++ {
+				app := setupTestApp(t)
+				if err := app.StartExecution(args); err != nil {
+					t.Fatalf("Run %d failed with error: %v", run, err)
+				}
+				if got, want := groupMembers(t, dir, app.GetResults()), []string{"a.txt", "photos/a.txt"}; !slices.Equal(got, want) {
+					t.Errorf("Run %d: expected %v, got %v", run, want, got)
+				}
+				if summary := app.GetSummary(); summary.FilesScanned != 3 {
+					t.Errorf("Run %d: expected DuDe's own files not to be scanned, got %+v", run, summary)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/common/hashing"
+	database "DuDe/internal/db"
+	"DuDe/internal/models"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test_Staging_PartialCollisionIsResolvedByFullHash verifies that files sharing size, head and
+// tail but differing in the middle survive the partial stage and are then told apart by the full hash.
+func Test_Staging_PartialCollisionIsResolvedByFullHash(t *testing.T) {
+	app := setupTestApp(t)
+
+	const window = 1024 // PartialHashKiB: 1
+	base := bytes.Repeat([]byte("a"), 4*window)
+	middleDiffers := bytes.Clone(base)
+	middleDiffers[2*window] = 'b'
+
+	files := map[string][]byte{
+		"same1.bin":      base,
+		"same2.bin":      base,
+		"middle.bin":     middleDiffers,
+		"tail.bin":       append(bytes.Clone(base[:len(base)-1]), 'c'),
+		"uniquesize.bin": []byte("no other file has this size"),
+	}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:    []string{tempDir},
+		ResultsDir:     outDir,
+		CacheDir:       outDir,
+		UseCache:       true,
+		CPUs:           1,
+		BufSize:        1024,
+		PartialHashKiB: 1,
+	}
+
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	results := app.GetResults()
+	if len(results) != 1 || len(results[0].DuplicatesFound) != 1 {
+		t.Fatalf("Expected exactly 1 group with 1 duplicate, got %+v", results)
+	}
+	paths := []string{results[0].FilePath, results[0].DuplicatesFound[0].FilePath}
+	for _, name := range []string{"same1.bin", "same2.bin"} {
+		found := false
+		for _, p := range paths {
+			if filepath.Base(p) == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected %s in the duplicate group, got %v", name, paths)
+		}
+	}
+
+	db, err := database.InitializeDatabase(outDir)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	defer db.Close()
+
+	records, err := database.NewFileHashRepository(db).GetAll()
+	if err != nil {
+		t.Fatalf("failed to read cache: %v", err)
+	}
+
+	cached := make(map[string]string)
+	for _, r := range records {
+		cached[filepath.Base(r.FilePath)] = r.Hash
+	}
+	if _, ok := cached["uniquesize.bin"]; ok {
+		t.Error("Expected the file with a unique size never to be hashed")
+	}
+	if hash, ok := cached["tail.bin"]; !ok || hash != "" {
+		t.Errorf("Expected tail.bin to be cached with a partial hash only, got %q (cached: %v)", hash, ok)
+	}
+	if hash := cached["middle.bin"]; hash == "" {
+		t.Error("Expected middle.bin to be fully hashed after its partial hash collided")
+	}
+}
+
+// Test_Staging_SmallFilesAreReadOnce verifies that files of at most two windows, which the
+// partial stage reads completely, get their full hash from it: the duplicates are found
+// with the hash of the whole file, and a file dropped by the partial stage is cached with it.
+func Test_Staging_SmallFilesAreReadOnce(t *testing.T) {
+	app := setupTestApp(t)
+
+	files := map[string][]byte{
+		"small1.txt": []byte("small duplicate"),
+		"small2.txt": []byte("small duplicate"),
+		"other.txt":  []byte("other same size"),
+	}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:    []string{tempDir},
+		ResultsDir:     outDir,
+		CacheDir:       outDir,
+		UseCache:       true,
+		CPUs:           1,
+		BufSize:        1024,
+		PartialHashKiB: 1,
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	db, err := database.OpenCache(outDir)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	defer db.Close()
+	records, err := database.NewFileHashRepository(db).GetAll()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("Expected the 3 files in the cache, got %d (%v)", len(records), err)
+	}
+	for _, r := range records {
+		hasher, err := hashing.Get(r.HashAlgorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := hashing.HashFile(context.Background(), hasher, r.FilePath, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Hash != want || r.PartialHash != want {
+			t.Errorf("Expected %s to be cached with the hash of the whole file %q, got %+v", filepath.Base(r.FilePath), want, r)
+		}
+	}
+
+	results := app.GetResults()
+	if len(results) != 1 || len(results[0].DuplicatesFound) != 1 {
+		t.Errorf("Expected exactly 1 group with 1 duplicate, got %+v", results)
+	}
+}
+
+// Test_Staging_PartialWindowChangeKeepsDuplicates verifies that a cached partial hash is only
+// compared with partial hashes taken with the same window: after the window changed, a
+// touched copy is hashed with the new window and must still match its untouched twin.
+func Test_Staging_PartialWindowChangeKeepsDuplicates(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4096) // 64 KiB, larger than both windows
+	files := map[string][]byte{"a.bin": content, "b.bin": content}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:    []string{tempDir},
+		ResultsDir:     outDir,
+		CacheDir:       outDir,
+		UseCache:       true,
+		CPUs:           1,
+		BufSize:        1024,
+		PartialHashKiB: 4,
+	}
+	for run, kib := range []int{4, 8} {
+		if run == 1 {
+			later := time.Now().Add(time.Hour)
+			if err := os.Chtimes(filepath.Join(tempDir, "b.bin"), later, later); err != nil {
+				t.Fatal(err)
+			}
+		}
+		args.PartialHashKiB = kib
+		app := setupTestApp(t)
+		if err := app.StartExecution(args); err != nil {
+			t.Fatalf("Run with %d KiB: E2E app failed with error: %v", kib, err)
+		}
+		if results := app.GetResults(); len(results) != 1 || len(results[0].DuplicatesFound) != 1 {
+			t.Errorf("Run with %d KiB: expected 1 group with 1 duplicate, got %+v", kib, results)
+		}
+	}
+}
+
+// Test_Pipeline_SmallBuffersDoNotDeadlock runs many files through the streaming pipeline with
+// the smallest possible channel buffers, so every stage constantly applies backpressure.
+func Test_Pipeline_SmallBuffersDoNotDeadlock(t *testing.T) {
+	app := setupTestApp(t)
+
+	files := make(map[string][]byte)
+	for i := range 200 {
+		content := []byte(fmt.Sprintf("content %d", i%50)) // 50 groups of 4 duplicates
+		files[fmt.Sprintf("dir%d/file%d.txt", i%7, i)] = content
+	}
+	tempDir, cleanup := createTestFilesByteArray(t, files)
+	defer func() { cleanup(); deleteTestFolder(t) }()
+
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories: []string{tempDir},
+		ResultsDir:  outDir,
+		CacheDir:    outDir,
+		UseCache:    true,
+		CPUs:        2,
+		BufSize:     1,
+	}
+
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+
+	results := app.GetResults()
+	if len(results) != 50 {
+		t.Fatalf("Expected 50 duplicate groups, got %d", len(results))
+	}
+	for _, group := range results {
+		if len(group.DuplicatesFound) != 3 {
+			t.Errorf("Expected 3 duplicates of %s, got %d", group.FilePath, len(group.DuplicatesFound))
+		}
+	}
+}
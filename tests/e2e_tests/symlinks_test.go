@@ -0,0 +1,139 @@
+package e2e_tests
+
+import (
+	"DuDe/internal/cli"
+	"DuDe/internal/models"
+	process "DuDe/internal/processing"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var symlinkTestFiles = map[string][]byte{
+	"a.txt":     []byte("linked content"),
+	"dir/b.txt": []byte("other content"),
+}
+
+// symlinkTestTree creates symlinkTestFiles with symlinks to a file, to a directory inside the
+// tree, to the tree itself, to a directory outside it and to nothing. The outside directory
+// holds a copy of a.txt and a symlink to itself.
+func symlinkTestTree(t *testing.T) string {
+	t.Helper()
+	dir, cleanup := createTestFilesByteArray(t, symlinkTestFiles)
+	t.Cleanup(func() { cleanup(); deleteTestFolder(t) })
+
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "x.txt"), []byte("linked content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{
+		filepath.Join(dir, "link.txt"):   "a.txt",
+		filepath.Join(dir, "dirlink"):    "dir",
+		filepath.Join(dir, "loop"):       ".",
+		filepath.Join(dir, "ext"):        outside,
+		filepath.Join(dir, "broken"):     "missing.txt",
+		filepath.Join(outside, "itself"): ".",
+	} {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported here: %v", err)
+		}
+	}
+	return dir
+}
+
+// scanSymlinks scans dir with the symlink policy and returns the app for inspection.
+func scanSymlinks(t *testing.T, dir, policy string) *process.FrontendApp {
+	t.Helper()
+	app := setupTestApp(t)
+	outDir := t.TempDir()
+	args := models.ExecutionParams{
+		Directories:   []string{dir},
+		ResultsDir:    outDir,
+		CacheDir:      outDir,
+		CPUs:          1,
+		BufSize:       1024,
+		SymlinkPolicy: policy,
+	}
+	if err := app.StartExecution(args); err != nil {
+		t.Fatalf("E2E app failed with error: %v", err)
+	}
+	return app
+}
+
+func Test_Symlinks_SkippedByDefault(t *testing.T) {
+	dir := symlinkTestTree(t)
+
+	app := scanSymlinks(t, dir, "")
+	if len(app.GetResults()) != 0 || len(app.GetSymlinks()) != 0 {
+		t.Errorf("Expected symlinks to be ignored, got %+v and %+v", app.GetResults(), app.GetSymlinks())
+	}
+}
+
+func Test_Symlinks_Followed(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("a followed symlink is only recognised as its target on Linux")
+	}
+	dir := symlinkTestTree(t)
+
+	app := scanSymlinks(t, dir, "follow")
+
+	// The loops end, the symlinked dir is walked once and link.txt is a.txt under another path.
+	if got, want := groupMembers(t, dir, app.GetResults()), []string{"a.txt", "ext/x.txt"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if summary := app.GetSummary(); summary.FilesScanned != 3 {
+		t.Errorf("Expected every file to be scanned once, got %+v", summary)
+	}
+	sets := app.GetHardLinks()
+	if len(sets) != 1 || !strings.HasSuffix(sets[0].FilePath, "a.txt") || len(sets[0].HardLinks) != 1 {
+		t.Errorf("Expected link.txt to be collapsed into a.txt, got %+v", sets)
+	}
+}
+
+func Test_Symlinks_Reported(t *testing.T) {
+	dir := symlinkTestTree(t)
+
+	app := scanSymlinks(t, dir, "report")
+	if len(app.GetResults()) != 0 {
+		t.Errorf("Expected reported symlinks not to be scanned, got %+v", app.GetResults())
+	}
+
+	var got []string
+	for _, link := range app.GetSymlinks() {
+		rel, err := filepath.Rel(dir, link.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+		if link.Broken != (rel == "broken") {
+			t.Errorf("Expected only the dangling link to be broken, got %+v", link)
+		}
+		if rel == "link.txt" && link.Target != "a.txt" {
+			t.Errorf("Expected the target as stored in the link, got %q", link.Target)
+		}
+	}
+	if want := []string{"broken", "dirlink", "ext", "link.txt", "loop"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func Test_CLI_Scan_Symlinks(t *testing.T) {
+	dir := symlinkTestTree(t)
+	outDir := t.TempDir()
+
+	code, out := runCLI(t, "scan", "-quiet", "-symlinks", "report", "-results-dir", outDir, "-cache-dir", outDir, dir)
+	if code != cli.ExitOK {
+		t.Fatalf("Expected no duplicates, got exit code %d: %s", code, out)
+	}
+	if !strings.Contains(out, "5 symlinks") || !strings.Contains(out, filepath.Join(dir, "broken")+" -> missing.txt (broken)") {
+		t.Errorf("Expected the symlinks to be listed, got:\n%s", out)
+	}
+
+	code, _ = runCLI(t, "scan", "-quiet", "-symlinks", "resolve", "-results-dir", outDir, "-cache-dir", outDir, dir)
+	if code != cli.ExitError {
+		t.Errorf("Expected an unknown symlink policy to fail, got exit code %d", code)
+	}
+}
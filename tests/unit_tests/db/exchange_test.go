@@ -0,0 +1,132 @@
+package unit_test
+
+import (
+	database "DuDe/internal/db"
+	"DuDe/internal/models/db_models"
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// exchangeCache returns a fresh cache holding a full and a partial-only entry below
+// /data and a full entry elsewhere.
+func exchangeCache(t *testing.T) *database.FileHashRepository {
+	t.Helper()
+	db, err := database.InitializeDatabase(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewFileHashRepository(db)
+	if err := repo.UpsertBatch([]*db_models.FileHash{
+		{FilePath: filepath.FromSlash("/data/photos/a.jpg"), Hash: "full", HashAlgorithm: "md5", PartialHash: "partial", PartialWindow: 4096, FileSize: 10, ModTime: "2024-01-01T00:00:00Z"},
+		{FilePath: filepath.FromSlash("/data/b.jpg"), HashAlgorithm: "md5", PartialHash: "partial", FileSize: 10, ModTime: "2024-01-01T00:00:00Z"},
+		{FilePath: filepath.FromSlash("/music/c.mp3"), Hash: "other", HashAlgorithm: "md5", FileSize: 20, ModTime: "2024-01-01T00:00:00Z"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestExportImportReRooted(t *testing.T) {
+	var export bytes.Buffer
+	n, err := database.ExportCache(exchangeCache(t).Db, &export, filepath.FromSlash("/data"))
+	if err != nil {
+		t.Fatalf("ExportCache failed: %v", err)
+	}
+	// Only full hashes below the root are worth sharing.
+	if n != 1 || !strings.Contains(export.String(), `"path":"photos/a.jpg"`) {
+		t.Fatalf("Expected photos/a.jpg only, got %d entries:\n%s", n, export.String())
+	}
+
+	target := exchangeCache(t)
+	if _, err := target.Db.Exec("DELETE FROM file_hashes WHERE path LIKE '%a.jpg'"); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.FromSlash("/mnt/archive/photos/a.jpg")
+	if err := target.Upsert(&db_models.FileHash{FilePath: existing, Hash: "mine", HashAlgorithm: "md5", FileSize: 10, ModTime: "t"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		root string
+		want database.ImportReport
+	}{
+		{root: filepath.FromSlash("/mnt/data"), want: database.ImportReport{Imported: 1}},
+		{root: filepath.FromSlash("/mnt/archive"), want: database.ImportReport{Skipped: 1}},
+	} {
+		report, err := database.ImportCache(target.Db, bytes.NewReader(export.Bytes()), tt.root)
+		if err != nil {
+			t.Fatalf("ImportCache(%s) failed: %v", tt.root, err)
+		}
+		if *report != tt.want {
+			t.Errorf("ImportCache(%s): expected %+v, got %+v", tt.root, tt.want, *report)
+		}
+	}
+
+	records, err := target.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported []string
+	for _, r := range records {
+		if r.Imported {
+			imported = append(imported, r.FilePath)
+			if r.Hash != "full" || r.PartialHash != "partial" || r.PartialWindow != 4096 || r.FileSize != 10 {
+				t.Errorf("Unexpected imported record %+v", r)
+			}
+		} else if r.FilePath == existing && r.Hash != "mine" {
+			t.Errorf("Expected the cache's own entry to be kept, got %+v", r)
+		}
+	}
+	if want := []string{filepath.FromSlash("/mnt/data/photos/a.jpg")}; !slices.Equal(imported, want) {
+		t.Errorf("Expected %v to be imported, got %v", want, imported)
+	}
+
+	// A scan saving the entry makes it the cache's own.
+	if err := target.Upsert(&db_models.FileHash{FilePath: imported[0], Hash: "full", HashAlgorithm: "md5", PartialHash: "partial", FileSize: 10, ModTime: "2024-01-01T00:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	if records, err := target.GetUnder(filepath.FromSlash("/mnt/data")); err != nil || len(records) != 1 || records[0].Imported {
+		t.Errorf("Expected the saved entry to no longer be imported, got %+v (%v)", records, err)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	repo := exchangeCache(t)
+	var absolute, relative bytes.Buffer
+	if _, err := database.ExportCache(repo.Db, &absolute, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.ExportCache(repo.Db, &relative, filepath.FromSlash("/data")); err != nil {
+		t.Fatal(err)
+	}
+	escaping := strings.Replace(relative.String(), `"path":"photos/a.jpg"`, `"path":"../etc/a.jpg"`, 1)
+
+	for _, tt := range []struct {
+		name   string
+		export string
+		root   string
+		want   error
+	}{
+		{name: "Relative export without root", export: relative.String(), want: database.ErrImportRoot},
+		{name: "Absolute export with root", export: absolute.String(), root: filepath.FromSlash("/mnt"), want: database.ErrAbsoluteRoot},
+		{name: "Not an export", export: `{"type":"group"}`, root: filepath.FromSlash("/mnt"), want: database.ErrNotAnExport},
+		{name: "Newer version", export: strings.Replace(relative.String(), `"version":1`, `"version":99`, 1), root: filepath.FromSlash("/mnt"), want: database.ErrExportVersion},
+		{name: "Path leaving the root", export: escaping, root: filepath.FromSlash("/mnt"), want: database.ErrOutsideRoot},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := database.ImportCache(repo.Db, strings.NewReader(tt.export), tt.root); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// A failed import adds nothing.
+	if records, err := repo.GetUnder(filepath.FromSlash("/mnt")); err != nil || len(records) != 0 {
+		t.Errorf("Expected no imported entries, got %+v (%v)", records, err)
+	}
+}
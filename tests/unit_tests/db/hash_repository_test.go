@@ -0,0 +1,112 @@
+package unit_test
+
+import (
+	database "DuDe/internal/db"
+	"DuDe/internal/models/db_models"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUpsertBatch(t *testing.T) {
+	db, err := database.InitializeDatabase(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := database.NewFileHashRepository(db)
+
+	var batch []*db_models.FileHash
+	for i := range 3 {
+		batch = append(batch, &db_models.FileHash{FilePath: fmt.Sprintf("/data/%d.txt", i), Hash: "h", HashAlgorithm: "md5", FileSize: 1, ModTime: "t"})
+	}
+	if err := repo.UpsertBatch(batch); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+
+	// Saving the same records again changes nothing; a changed one is updated in place.
+	batch[1].Hash = "changed"
+	if err := repo.UpsertBatch(batch); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+	records, err := repo.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	for i, want := range []string{"h", "changed", "h"} {
+		r, err := repo.GetByPath(batch[i].FilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Hash != want || r.UpdatedAt.Valid != (i == 1) {
+			t.Errorf("Expected %s to hold %q and only the changed record to be updated, got %+v", r.FilePath, want, r)
+		}
+	}
+}
+
+func TestDatabaseUsesWAL(t *testing.T) {
+	db, err := database.InitializeDatabase(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Errorf("Expected the WAL journal mode, got %q (%v)", mode, err)
+	}
+}
+
+func TestGetUnder(t *testing.T) {
+	if filepath.Separator != '/' {
+		t.Skip("paths below are Unix paths")
+	}
+	db, err := database.InitializeDatabase(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := database.NewFileHashRepository(db)
+
+	var batch []*db_models.FileHash
+	for _, path := range []string{"/data/a.txt", "/data/sub/b.txt", "/data2/c.txt", "/dat", "/data", "/data.txt", "/music/d.txt"} {
+		batch = append(batch, &db_models.FileHash{FilePath: path, Hash: "h", HashAlgorithm: "md5", FileSize: 1, ModTime: "t"})
+	}
+	if err := repo.UpsertBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{"/data", "/data/"} {
+		records, err := repo.GetUnder(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, r := range records {
+			paths = append(paths, r.FilePath)
+		}
+		slices.Sort(paths)
+		if want := []string{"/data/a.txt", "/data/sub/b.txt"}; !slices.Equal(paths, want) {
+			t.Errorf("GetUnder(%q) = %v, want %v", dir, paths, want)
+		}
+	}
+	if records, err := repo.GetUnder("/"); err != nil || len(records) != len(batch) {
+		t.Errorf("Expected every record below /, got %d (%v)", len(records), err)
+	}
+
+	// The lookup must not scan the whole table.
+	var id, parent, notUsed int
+	var plan string
+	if err := db.QueryRow("EXPLAIN QUERY PLAN SELECT path FROM file_hashes WHERE path >= ? AND path < ?", "/data/", "/data0").
+		Scan(&id, &parent, &notUsed, &plan); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(plan, "USING") || !strings.Contains(plan, "INDEX") {
+		t.Errorf("Expected the path range to use an index, got plan %q", plan)
+	}
+}
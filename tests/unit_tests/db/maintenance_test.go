@@ -0,0 +1,106 @@
+package unit_test
+
+import (
+	"DuDe/internal/common"
+	database "DuDe/internal/db"
+	"DuDe/internal/models/db_models"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatsOfUpgradedCache(t *testing.T) {
+	dir := loadFixture(t, "v0_baseline.sql")
+	db, err := database.OpenCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stats, err := database.Stats(db, dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 2 || stats.SizeBytes == 0 || stats.SchemaVersion != database.LatestSchemaVersion() {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	// Entries from before seen_at count as seen when they were written.
+	if len(stats.Oldest) != 1 || stats.Oldest[0] != (database.CacheEntry{Path: "/data/a.txt", SeenAt: "2024-01-02T00:00:00Z"}) {
+		t.Errorf("Expected the oldest entry to be seen at its creation, got %+v", stats.Oldest)
+	}
+}
+
+func TestOpenCacheDoesNotCreateOne(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := database.OpenCache(dir); !errors.Is(err, database.ErrNoCache) {
+		t.Errorf("Expected ErrNoCache, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, common.MemFilename)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected no cache to be created, got %v", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	files := t.TempDir()
+	existing := filepath.Join(files, "kept.txt")
+	if err := os.WriteFile(existing, []byte("kept"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries := map[string]string{ // path: seen_at
+		existing:                             "2999-01-01T00:00:00Z",
+		filepath.Join(files, "gone.txt"):     "2999-01-01T00:00:00Z",
+		filepath.Join(files, "old.txt"):      "2000-01-01T00:00:00Z",
+		filepath.Join(files, "sub", "a.txt"): "2999-01-01T00:00:00Z",
+	}
+
+	for _, tt := range []struct {
+		name string
+		req  database.PruneRequest
+		want database.PruneReport
+	}{
+		{name: "Missing files", req: database.PruneRequest{Missing: true}, want: database.PruneReport{Missing: 3, EntriesLeft: 1}},
+		{name: "Old entries", req: database.PruneRequest{OlderThanDays: 30}, want: database.PruneReport{Old: 1, EntriesLeft: 3}},
+		{name: "Prefix", req: database.PruneRequest{Prefixes: []string{filepath.Join(files, "sub")}}, want: database.PruneReport{Prefixed: 1, EntriesLeft: 3}},
+		{name: "Every criterion", req: database.PruneRequest{Prefixes: []string{files}, OlderThanDays: 30, Vacuum: true}, want: database.PruneReport{Old: 1, Prefixed: 3}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			db, err := database.InitializeDatabase(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			for path, seen := range entries {
+				if err := database.NewFileHashRepository(db).Upsert(&db_models.FileHash{FilePath: path, Hash: "h", HashAlgorithm: "md5", FileSize: 1, ModTime: "t"}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := db.Exec("UPDATE file_hashes SET seen_at = ? WHERE path = ?", seen, path); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report, err := database.Prune(context.Background(), db, dir, tt.req)
+			if err != nil {
+				t.Fatalf("Prune failed: %v", err)
+			}
+			got := *report
+			got.SizeBefore, got.SizeAfter = 0, 0
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Nothing selected", func(t *testing.T) {
+		db, err := database.InitializeDatabase(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		if _, err := database.Prune(context.Background(), db, t.TempDir(), database.PruneRequest{Vacuum: true}); !errors.Is(err, database.ErrNothingToPrune) {
+			t.Errorf("Expected ErrNothingToPrune, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package unit_test
+
+import (
+	"DuDe/internal/common"
+	database "DuDe/internal/db"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadFixture writes the cache of an older release, built by the SQL script in testdata,
+// into a fresh directory and returns that directory.
+func loadFixture(t *testing.T, name string) string {
+	t.Helper()
+	script, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, common.MemFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(string(script)); err != nil {
+		t.Fatalf("fixture %s: %v", name, err)
+	}
+	return dir
+}
+
+func TestMigrateFixtures(t *testing.T) {
+	for _, tt := range []struct {
+		fixture    string
+		algorithms map[string]string
+	}{
+		{fixture: "v0_baseline.sql", algorithms: map[string]string{"/data/a.txt": "md5", "/data/b.txt": "md5"}},
+		{fixture: "v2_hash_algorithm.sql", algorithms: map[string]string{"/data/a.txt": "md5", "/data/b.txt": "sha256"}},
+		{fixture: "v3_partial_hash.sql", algorithms: map[string]string{"/data/a.txt": "md5", "/data/b.txt": "md5"}},
+	} {
+		t.Run(tt.fixture, func(t *testing.T) {
+			dir := loadFixture(t, tt.fixture)
+
+			// Opening twice proves the upgraded cache is left alone the second time.
+			for run := 1; run <= 2; run++ {
+				db, err := database.InitializeDatabase(dir)
+				if err != nil {
+					t.Fatalf("Run %d: failed to upgrade: %v", run, err)
+				}
+				if version, err := database.SchemaVersion(db); err != nil || version != database.LatestSchemaVersion() {
+					t.Errorf("Run %d: expected schema version %d, got %d (%v)", run, database.LatestSchemaVersion(), version, err)
+				}
+
+				records, err := database.NewFileHashRepository(db).GetAll()
+				if err != nil {
+					t.Fatalf("Run %d: failed to read the upgraded cache: %v", run, err)
+				}
+				if len(records) != len(tt.algorithms) {
+					t.Fatalf("Run %d: expected %d records to survive, got %d", run, len(tt.algorithms), len(records))
+				}
+				for _, r := range records {
+					if r.HashAlgorithm != tt.algorithms[r.FilePath] || r.Hash == "" || r.FileSize == 0 {
+						t.Errorf("Run %d: unexpected record %+v", run, r)
+					}
+				}
+
+				var applied int
+				if err := db.QueryRow("SELECT COUNT(*) FROM schema_version").Scan(&applied); err != nil || applied != database.LatestSchemaVersion() {
+					t.Errorf("Run %d: expected one schema_version row per migration, got %d (%v)", run, applied, err)
+				}
+				db.Close()
+			}
+		})
+	}
+}
+
+func TestMigrateNewCache(t *testing.T) {
+	dir := t.TempDir()
+	db, err := database.InitializeDatabase(dir)
+	if err != nil {
+		t.Fatalf("failed to create the cache: %v", err)
+	}
+	defer db.Close()
+
+	if version, err := database.SchemaVersion(db); err != nil || version != database.LatestSchemaVersion() {
+		t.Errorf("Expected schema version %d, got %d (%v)", database.LatestSchemaVersion(), version, err)
+	}
+	if _, err := db.Exec("INSERT INTO file_hashes (path, hash, size, modified_time) VALUES ('/a', 'h', 1, 't')"); err != nil {
+		t.Fatal(err)
+	}
+	var algorithm, partial string
+	if err := db.QueryRow("SELECT hash_algorithm, partial_hash FROM file_hashes WHERE path = '/a'").Scan(&algorithm, &partial); err != nil {
+		t.Fatal(err)
+	}
+	if algorithm != "md5" || partial != "" {
+		t.Errorf("Expected the column defaults, got %q and %q", algorithm, partial)
+	}
+}
+
+func TestMigrateRejectsNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+	db, err := database.InitializeDatabase(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'from the future', '')", database.LatestSchemaVersion()+1)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := database.InitializeDatabase(dir); !errors.Is(err, database.ErrSchemaTooNew) {
+		t.Errorf("Expected ErrSchemaTooNew, got %v", err)
+	}
+}
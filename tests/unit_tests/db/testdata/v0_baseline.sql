@@ -0,0 +1,13 @@
+-- Cache written by the first release: no schema_version, no hash algorithm, no partial hash.
+CREATE TABLE file_hashes (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        path TEXT UNIQUE,
+        hash TEXT,
+        size INTEGER,
+        modified_time TEXT,
+        updated_at TEXT,
+        created_at TEXT
+);
+INSERT INTO file_hashes (path, hash, size, modified_time, created_at) VALUES
+        ('/data/a.txt', '9e107d9d372bb6826bd81d3542a419d6', 17, '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z'),
+        ('/data/b.txt', 'e4d909c290d0fb1ca068ffaddf22cbd0', 23, '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z');
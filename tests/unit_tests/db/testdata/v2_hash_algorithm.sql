@@ -0,0 +1,14 @@
+-- Cache written once the hash algorithm was recorded, before partial hashes and schema_version.
+CREATE TABLE file_hashes (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        path TEXT UNIQUE,
+        hash TEXT,
+        hash_algorithm TEXT NOT NULL DEFAULT 'md5',
+        size INTEGER,
+        modified_time TEXT,
+        updated_at TEXT,
+        created_at TEXT
+);
+INSERT INTO file_hashes (path, hash, hash_algorithm, size, modified_time, created_at) VALUES
+        ('/data/a.txt', '9e107d9d372bb6826bd81d3542a419d6', 'md5', 17, '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z'),
+        ('/data/b.txt', 'd7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592', 'sha256', 23, '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z');
@@ -0,0 +1,15 @@
+-- Cache written by the last release before schema_version: every column, but no record of it.
+CREATE TABLE file_hashes (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        path TEXT UNIQUE,
+        hash TEXT,
+        hash_algorithm TEXT NOT NULL DEFAULT 'md5',
+        partial_hash TEXT NOT NULL DEFAULT '',
+        size INTEGER,
+        modified_time TEXT,
+        updated_at TEXT,
+        created_at TEXT
+);
+INSERT INTO file_hashes (path, hash, hash_algorithm, partial_hash, size, modified_time, created_at) VALUES
+        ('/data/a.txt', '9e107d9d372bb6826bd81d3542a419d6', 'md5', '9e107d9d372bb6826bd81d3542a419d6', 17, '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z'),
+        ('/data/b.txt', 'e4d909c290d0fb1ca068ffaddf22cbd0', 'md5', 'e4d909c290d0fb1ca068ffaddf22cbd0', 23, '2024-01-01T00:00:00Z', '2024-01-02T00:00:00Z');
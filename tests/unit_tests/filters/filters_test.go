@@ -0,0 +1,111 @@
+package unit_test
+
+import (
+	"DuDe/internal/filters"
+	"DuDe/internal/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{pattern: "*.tmp", rel: "a.tmp", want: true},
+		{pattern: "*.tmp", rel: "deep/down/a.tmp", want: true},
+		{pattern: "node_modules", rel: "app/node_modules", want: true},
+		{pattern: "node_modules", rel: "app/node_modules/x/index.js", want: true},
+		{pattern: "node_modules", rel: "app/node_modules_old", want: false},
+		{pattern: "photos/*.jpg", rel: "photos/a.jpg", want: true},
+		{pattern: "photos/*.jpg", rel: "backup/photos/a.jpg", want: false},
+		{pattern: "photos/*.jpg", rel: "photos/2024/a.jpg", want: false},
+		{pattern: "photos/**/*.jpg", rel: "photos/a.jpg", want: true},
+		{pattern: "photos/**/*.jpg", rel: "photos/2024/06/a.jpg", want: true},
+		{pattern: "**/cache", rel: "a/b/cache", want: true},
+		{pattern: "build/", rel: "build", want: true},
+	}
+
+	for _, tt := range testCases {
+		if got := filters.Match(tt.pattern, tt.rel); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f, err := filters.New(models.ExecutionParams{
+		Include:           []string{"*.jpg", "*.png", "*.log"},
+		Exclude:           []string{"node_modules", "*.tmp.jpg"},
+		ExcludeExtensions: []string{"LOG"},
+		MinSize:           10,
+		MaxSize:           100,
+		ModifiedAfter:     "2024-01-01T00:00:00Z",
+		ModifiedBefore:    "2024-07-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for rel, want := range map[string]bool{
+		"a.jpg":                  true,
+		"sub/b.PNG":              false, // globs are case-sensitive
+		"c.txt":                  false,
+		"d.tmp.jpg":              false,
+		"e.log":                  false,
+		"node_modules/x/f.jpg":   false,
+		"other/node_modules.jpg": true,
+	} {
+		if got := f.MatchPath(rel); got != want {
+			t.Errorf("MatchPath(%q) = %v, want %v", rel, got, want)
+		}
+	}
+
+	if !f.SkipDir("app/node_modules") || f.SkipDir("app") || f.SkipDir(".") {
+		t.Errorf("Expected only excluded directories to be skipped")
+	}
+
+	inRange := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		size    int64
+		modTime time.Time
+		want    bool
+	}{
+		{size: 10, modTime: inRange, want: true},
+		{size: 100, modTime: inRange, want: true},
+		{size: 9, modTime: inRange, want: false},
+		{size: 101, modTime: inRange, want: false},
+		{size: 50, modTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), want: true},
+		{size: 50, modTime: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), want: false},
+		{size: 50, modTime: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), want: false},
+	} {
+		if got := f.MatchInfo(tt.size, tt.modTime); got != tt.want {
+			t.Errorf("MatchInfo(%d, %s) = %v, want %v", tt.size, tt.modTime, got, tt.want)
+		}
+	}
+}
+
+func TestNilFilterMatchesEverything(t *testing.T) {
+	var f *filters.Filter
+	if f.SkipDir("a") || !f.MatchPath("a/b.c") || !f.MatchInfo(0, time.Time{}) {
+		t.Errorf("Expected a nil filter to match everything")
+	}
+}
+
+func TestNormalizeSymlinkPolicy(t *testing.T) {
+	for policy, want := range map[string]string{
+		"":         filters.DefaultSymlinkPolicy,
+		" Follow ": filters.SymlinkFollow,
+		"report":   filters.SymlinkReport,
+		"skip":     filters.SymlinkSkip,
+	} {
+		if got, err := filters.NormalizeSymlinkPolicy(policy); err != nil || got != want {
+			t.Errorf("NormalizeSymlinkPolicy(%q) = %q, %v, want %q", policy, got, err, want)
+		}
+	}
+	if _, err := filters.NormalizeSymlinkPolicy("resolve"); !errors.Is(err, filters.ErrUnknownSymlinkPolicy) {
+		t.Errorf("Expected ErrUnknownSymlinkPolicy, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package unit_test
+
+import (
+	"DuDe/internal/filters"
+	"DuDe/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadIgnores writes the given ignore files below a temporary root and loads them
+// the way the walker does, from the root downwards.
+func loadIgnores(t *testing.T, files map[string]string) *filters.Ignores {
+	t.Helper()
+	f, err := filters.New(models.ExecutionParams{RespectIgnoreFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ig := f.NewIgnores()
+
+	root := t.TempDir()
+	dirs := map[string]bool{".": true}
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dirs[filepath.ToSlash(filepath.Dir(rel))] = true
+	}
+	for dir := range dirs {
+		if err := ig.Load(filepath.Join(root, filepath.FromSlash(dir)), dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ig
+}
+
+func TestIgnores(t *testing.T) {
+	ig := loadIgnores(t, map[string]string{
+		".gitignore": "# build outputs\n" +
+			"*.o\n" +
+			"build/\n" +
+			"/root-only.txt\n" +
+			"docs/*.pdf\n" +
+			"*.log\n" +
+			"!keep.log\n",
+		"app/.gitignore":  "!*.o\nsecret.txt\n",
+		"app/.dudeignore": "!secret.txt\nlarge/**\n",
+	})
+
+	testCases := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{rel: "main.o", want: true},
+		{rel: "lib/util.o", want: true},
+		{rel: "app/main.o", want: false},        // a deeper file re-includes it
+		{rel: "build", isDir: true, want: true}, // directory-only pattern
+		{rel: "src/build", isDir: true, want: true},
+		{rel: "build", want: false}, // a file named build is not a directory
+		{rel: "root-only.txt", want: true},
+		{rel: "sub/root-only.txt", want: false}, // anchored to the root
+		{rel: "docs/a.pdf", want: true},
+		{rel: "docs/old/a.pdf", want: false},
+		{rel: "x.log", want: true},
+		{rel: "keep.log", want: false},       // negated by a later line
+		{rel: "app/secret.txt", want: false}, // .dudeignore overrides .gitignore
+		{rel: "app/large", isDir: true, want: true},
+		{rel: "large", isDir: true, want: false},
+		{rel: ".", isDir: true, want: false},
+	}
+
+	for _, tt := range testCases {
+		if got := ig.Ignored(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoresDisabled(t *testing.T) {
+	f, err := filters.New(models.ExecutionParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ig := f.NewIgnores(); ig.Ignored("a.o", false) || ig.Load(t.TempDir(), ".") != nil {
+		t.Errorf("Expected ignore files to be ignored unless enabled")
+	}
+	if !f.SkipDir("src/.git") || f.SkipDir("src/git") {
+		t.Errorf("Expected VCS directories to be skipped by default")
+	}
+
+	f, err = filters.New(models.ExecutionParams{ScanVCSDirs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.SkipDir("src/.git") {
+		t.Errorf("Expected VCS directories to be scanned when enabled")
+	}
+}
@@ -0,0 +1,93 @@
+package unit_test
+
+import (
+	"DuDe/internal/filters"
+	"DuDe/internal/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "real")
+	if err := os.Mkdir(real, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks not supported here: %v", err)
+	}
+
+	want := filters.Canonical(real)
+	if got := filters.Canonical(link + string(filepath.Separator) + "."); got != want {
+		t.Errorf("Canonical(link) = %q, want %q", got, want)
+	}
+	// Paths that do not exist yet resolve as far as they exist.
+	if got := filters.Canonical(filepath.Join(link, "new", "dir")); got != filepath.Join(want, "new", "dir") {
+		t.Errorf("Canonical(link/new/dir) = %q, want it below %q", got, want)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	root := filepath.FromSlash("/data/photos")
+	for path, want := range map[string]bool{
+		"/data/photos":          true,
+		"/data/photos/2024":     true,
+		"/data/photos2":         false,
+		"/data":                 false,
+		"/data/photos/../music": false,
+	} {
+		if got := filters.Within(root, filepath.Clean(filepath.FromSlash(path))); got != want {
+			t.Errorf("Within(%q, %q) = %v, want %v", root, path, got, want)
+		}
+	}
+}
+
+func TestCollapseRoots(t *testing.T) {
+	roots := []string{
+		filepath.FromSlash("/data/photos"),
+		filepath.FromSlash("/data"),
+		filepath.FromSlash("/music"),
+		filepath.FromSlash("/data/"),
+		filepath.FromSlash("/music/../music/live"),
+	}
+	kept, dropped := filters.CollapseRoots(roots)
+	if want := []string{roots[1], roots[2]}; !slices.Equal(kept, want) {
+		t.Errorf("Expected %v to be kept, got %v", want, kept)
+	}
+	if want := []string{roots[0], roots[3], roots[4]}; !slices.Equal(dropped, want) {
+		t.Errorf("Expected %v to be dropped, got %v", want, dropped)
+	}
+}
+
+func TestOwnDirs(t *testing.T) {
+	root := t.TempDir()
+	f, err := filters.New(models.ExecutionParams{
+		Directories: []string{root},
+		CacheDir:    root,
+		ResultsDir:  filepath.Join(root, "results"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !f.OwnDir(root) || !f.OwnDir(filepath.Join(root, "results")) || f.OwnDir(filepath.Join(root, "photos")) {
+		t.Errorf("Expected only the cache and results directories to be DuDe's")
+	}
+	for name, want := range map[string]bool{
+		"memory.db":                       true,
+		"memory.db-wal":                   true,
+		"actions.journal":                 true,
+		"results_2024_01_01_00_00_00.csv": true,
+		"results.txt":                     false,
+		"photo.jpg":                       false,
+		"photos/memory.db":                false,
+		"results/results_2024_01_01.json": true,
+	} {
+		if got := f.OwnFile(filepath.Join(root, filepath.FromSlash(name))); got != want {
+			t.Errorf("OwnFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
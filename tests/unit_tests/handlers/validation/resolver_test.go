@@ -1,10 +1,16 @@
 package unit_test
 
 import (
+	"DuDe/internal/common/hashing"
+	"DuDe/internal/filters"
 	val "DuDe/internal/handlers/validation"
+	"DuDe/internal/keeprules"
 	"DuDe/internal/models"
+	"DuDe/internal/results"
 	"errors"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"testing"
 )
 
@@ -314,3 +320,302 @@ func TestResolveBufferSize(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveHashAlgorithm(t *testing.T) {
+	mockV := val.MockValidator{
+		// All paths are fine
+		ReadableDirFunc: func(p string) error { return nil },
+		WritableDirFunc: func(p string) error { return nil },
+	}
+	r := setupResolver(t, mockV)
+	testCases := []struct {
+		name     string
+		params   models.ExecutionParams
+		expected string
+		err      error
+	}{
+		{
+			name:     "Empty value defaults to md5",
+			params:   models.ExecutionParams{Directories: []string{"/placeholder"}, HashAlgorithm: ""},
+			expected: hashing.MD5,
+		},
+		{
+			name:     "Value is normalised",
+			params:   models.ExecutionParams{Directories: []string{"/placeholder"}, HashAlgorithm: " SHA256 "},
+			expected: hashing.SHA256,
+		},
+		{
+			name:   "Unknown value fails",
+			params: models.ExecutionParams{Directories: []string{"/placeholder"}, HashAlgorithm: "crc32"},
+			err:    hashing.ErrUnknownAlgorithm,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.ResolveAndValidateArgs(&tt.params, "")
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected %v but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("%s: Some error %v", tt.name, err)
+			}
+			if tt.params.HashAlgorithm != tt.expected {
+				t.Errorf("Expected %s but got %s", tt.expected, tt.params.HashAlgorithm)
+			}
+		})
+	}
+}
+
+func TestResolveKeepRules(t *testing.T) {
+	mockV := val.MockValidator{
+		// All paths are fine
+		ReadableDirFunc: func(p string) error { return nil },
+		WritableDirFunc: func(p string) error { return nil },
+	}
+	r := setupResolver(t, mockV)
+	testCases := []struct {
+		name  string
+		rules []string
+		err   error
+	}{
+		{name: "No rules", rules: nil},
+		{name: "Valid rules", rules: []string{"prefer-dir=/photos", "oldest", "name=*.orig"}},
+		{name: "Unknown rule fails", rules: []string{"biggest"}, err: keeprules.ErrInvalidRule},
+		{name: "Missing value fails", rules: []string{"prefer-dir="}, err: keeprules.ErrInvalidRule},
+		{name: "Bad pattern fails", rules: []string{"name=[a"}, err: keeprules.ErrInvalidRule},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			params := models.ExecutionParams{Directories: []string{"/placeholder"}, KeepRules: tt.rules}
+			err := r.ResolveAndValidateArgs(&params, "")
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestResolveResultFormats(t *testing.T) {
+	mockV := val.MockValidator{
+		// All paths are fine
+		ReadableDirFunc: func(p string) error { return nil },
+		WritableDirFunc: func(p string) error { return nil },
+	}
+	r := setupResolver(t, mockV)
+	testCases := []struct {
+		name     string
+		formats  []string
+		expected []string
+		err      error
+	}{
+		{name: "Empty value defaults to csv", formats: nil, expected: []string{results.CSV}},
+		{name: "Values are normalised and de-duplicated", formats: []string{" JSON", "csv", "json", ""}, expected: []string{results.JSON, results.CSV}},
+		{name: "Unknown value fails", formats: []string{"xml"}, err: results.ErrUnknownFormat},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			params := models.ExecutionParams{Directories: []string{"/placeholder"}, ResultFormats: tt.formats}
+			err := r.ResolveAndValidateArgs(&params, "")
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected %v but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("%s: Some error %v", tt.name, err)
+			}
+			if !slices.Equal(params.ResultFormats, tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, params.ResultFormats)
+			}
+		})
+	}
+}
+
+func TestResolveCSVOptions(t *testing.T) {
+	mockV := val.MockValidator{
+		// All paths are fine
+		ReadableDirFunc: func(p string) error { return nil },
+		WritableDirFunc: func(p string) error { return nil },
+	}
+	r := setupResolver(t, mockV)
+	testCases := []struct {
+		name      string
+		delimiter string
+		layout    int
+		expected  string
+		err       error
+	}{
+		{name: "Empty delimiter defaults to comma", expected: ","},
+		{name: "Semicolon", delimiter: ";", expected: ";"},
+		{name: "Tab by name", delimiter: "tab", expected: "\t"},
+		{name: "Multiple characters fail", delimiter: ";;", err: results.ErrInvalidDelimiter},
+		{name: "Quote fails", delimiter: `"`, err: results.ErrInvalidDelimiter},
+		{name: "Unknown layout fails", layout: 3, err: results.ErrUnknownCSVLayout},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			params := models.ExecutionParams{Directories: []string{"/placeholder"}, CSVDelimiter: tt.delimiter, CSVLayout: tt.layout}
+			err := r.ResolveAndValidateArgs(&params, "")
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected %v but got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("%s: Some error %v", tt.name, err)
+			}
+			if params.CSVDelimiter != tt.expected {
+				t.Errorf("Expected %q but got %q", tt.expected, params.CSVDelimiter)
+			}
+			if params.CSVLayout != results.DefaultCSVLayout {
+				t.Errorf("Expected the default layout, got %d", params.CSVLayout)
+			}
+		})
+	}
+}
+
+func TestResolveFilters(t *testing.T) {
+	mockV := val.MockValidator{
+		// All paths are fine
+		ReadableDirFunc: func(p string) error { return nil },
+		WritableDirFunc: func(p string) error { return nil },
+	}
+	r := setupResolver(t, mockV)
+	testCases := []struct {
+		name   string
+		params models.ExecutionParams
+		err    error
+	}{
+		{name: "No filters"},
+		{name: "Valid filters", params: models.ExecutionParams{
+			Include: []string{"*.jpg", "photos/**/*.raw"}, Exclude: []string{"node_modules"},
+			MinSize: 1, MaxSize: 1024, ModifiedAfter: "2024-01-01", ModifiedBefore: "2024-06-01T12:00:00Z",
+		}},
+		{name: "Bad include fails", params: models.ExecutionParams{Include: []string{"[a"}}, err: filters.ErrInvalidPattern},
+		{name: "Empty exclude fails", params: models.ExecutionParams{Exclude: []string{" "}}, err: filters.ErrInvalidPattern},
+		{name: "Negative size fails", params: models.ExecutionParams{MinSize: -1}, err: filters.ErrInvalidSize},
+		{name: "Min above max fails", params: models.ExecutionParams{MinSize: 10, MaxSize: 5}, err: filters.ErrInvalidSize},
+		{name: "Bad time fails", params: models.ExecutionParams{ModifiedAfter: "yesterday"}, err: filters.ErrInvalidTime},
+		{name: "Empty range fails", params: models.ExecutionParams{ModifiedAfter: "2024-06-01", ModifiedBefore: "2024-01-01"}, err: filters.ErrInvalidTime},
+		{name: "Symlink policy", params: models.ExecutionParams{SymlinkPolicy: "Follow"}},
+		{name: "Unknown symlink policy fails", params: models.ExecutionParams{SymlinkPolicy: "resolve"}, err: filters.ErrUnknownSymlinkPolicy},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			params.Directories = []string{"/placeholder"}
+			err := r.ResolveAndValidateArgs(&params, "")
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v but got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestResolveFiltersNormalises(t *testing.T) {
+	r := setupResolver(t, val.MockValidator{
+		ReadableDirFunc: func(p string) error { return nil },
+		WritableDirFunc: func(p string) error { return nil },
+	})
+	params := models.ExecutionParams{
+		Directories:   []string{"/placeholder"},
+		Extensions:    []string{"JPG", ".jpg", " png", ""},
+		ModifiedAfter: "2024-01-31T10:00:00Z",
+	}
+	if err := r.ResolveAndValidateArgs(&params, ""); err != nil {
+		t.Fatalf("Some error %v", err)
+	}
+	if !slices.Equal(params.Extensions, []string{".jpg", ".png"}) {
+		t.Errorf("Expected normalised extensions, got %v", params.Extensions)
+	}
+	if params.ModifiedAfter != "2024-01-31T10:00:00Z" {
+		t.Errorf("Expected an RFC 3339 bound, got %q", params.ModifiedAfter)
+	}
+}
+
+func TestResolveOverlappingRoots(t *testing.T) {
+	r := setupResolver(t, val.MockValidator{
+		ReadableDirFunc: func(p string) error { return nil },
+		WritableDirFunc: func(p string) error { return nil },
+	})
+	params := models.ExecutionParams{
+		Directories: []string{"/data/photos", "/data", "/music", "/data/"},
+		ResultsDir:  "results",
+	}
+	if err := r.ResolveAndValidateArgs(&params, "/exe"); err != nil {
+		t.Fatalf("Some error %v", err)
+	}
+	if want := []string{filepath.Clean("/data"), filepath.Clean("/music")}; runtime.GOOS != "windows" && !slices.Equal(params.Directories, want) {
+		t.Errorf("Expected nested and repeated roots to be collapsed into %v, got %v", want, params.Directories)
+	}
+	if !filepath.IsAbs(params.ResultsDir) {
+		t.Errorf("Expected an absolute results directory, got %q", params.ResultsDir)
+	}
+}
+
+func TestResolveReferenceDirs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("paths below are Unix paths")
+	}
+	r := setupResolver(t, val.MockValidator{
+		ReadableDirFunc: func(p string) error { return nil },
+		WritableDirFunc: func(p string) error { return nil },
+	})
+
+	t.Run("References are walked with the directories", func(t *testing.T) {
+		params := models.ExecutionParams{
+			Directories:   []string{"/data/inbox", "/archive"},
+			ReferenceDirs: []string{"/archive", "/archive/2024"},
+			ResultsDir:    "results",
+		}
+		if err := r.ResolveAndValidateArgs(&params, "/exe"); err != nil {
+			t.Fatalf("Some error %v", err)
+		}
+		if want := []string{"/data/inbox", "/archive"}; !slices.Equal(params.Directories, want) {
+			t.Errorf("Expected the roots %v, got %v", want, params.Directories)
+		}
+		if want := []string{"/archive"}; !slices.Equal(params.ReferenceDirs, want) {
+			t.Errorf("Expected the references %v, got %v", want, params.ReferenceDirs)
+		}
+	})
+
+	t.Run("Directory inside a reference", func(t *testing.T) {
+		params := models.ExecutionParams{Directories: []string{"/archive/inbox"}, ReferenceDirs: []string{"/archive"}, ResultsDir: "results"}
+		if err := r.ResolveAndValidateArgs(&params, "/exe"); !errors.Is(err, val.ErrInsideReference) {
+			t.Errorf("Expected ErrInsideReference, got %v", err)
+		}
+	})
+
+	t.Run("Only references", func(t *testing.T) {
+		params := models.ExecutionParams{ReferenceDirs: []string{"/archive"}, ResultsDir: "results"}
+		if err := r.ResolveAndValidateArgs(&params, "/exe"); !errors.Is(err, val.ErrNoDirectories) {
+			t.Errorf("Expected ErrNoDirectories, got %v", err)
+		}
+	})
+
+	t.Run("Unreadable reference", func(t *testing.T) {
+		r := setupResolver(t, val.MockValidator{
+			ReadableDirFunc: func(p string) error {
+				if p == "/archive" {
+					return val.ErrNoReadAccess
+				}
+				return nil
+			},
+			WritableDirFunc: func(p string) error { return nil },
+		})
+		params := models.ExecutionParams{Directories: []string{"/data"}, ReferenceDirs: []string{"/archive"}, ResultsDir: "results"}
+		if err := r.ResolveAndValidateArgs(&params, "/exe"); !errors.Is(err, val.ErrNoReadAccess) {
+			t.Errorf("Expected ErrNoReadAccess, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package unit_test
+
+import (
+	"DuDe/internal/keeprules"
+	"DuDe/internal/models"
+	"testing"
+)
+
+var files = []models.FileHash{
+	{FileName: "b.jpg", FilePath: "/photos/inbox/b.jpg", ModTime: "2024-03-01T10:00:00Z"},
+	{FileName: "a.orig", FilePath: "/photos/master/2020/a.orig", ModTime: "2024-02-01T10:00:00Z"},
+	{FileName: "c.jpg", FilePath: "/backup/c.jpg", ModTime: "2024-01-01T10:00:00Z"},
+	{FileName: "d.jpg", FilePath: "/photos/inbox/d.jpg", ModTime: "2024-01-01T10:00:00Z"},
+}
+
+func TestRulesGroup(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rules    []string
+		expected string
+	}{
+		{name: "No rules keeps the smallest path", rules: nil, expected: "/backup/c.jpg"},
+		{name: "Oldest, ties broken by path", rules: []string{"oldest"}, expected: "/backup/c.jpg"},
+		{name: "Newest", rules: []string{"newest"}, expected: "/photos/inbox/b.jpg"},
+		{name: "Shortest path", rules: []string{"shortest-path"}, expected: "/backup/c.jpg"},
+		{name: "Longest path", rules: []string{"longest-path"}, expected: "/photos/master/2020/a.orig"},
+		{name: "Preferred directory", rules: []string{"prefer-dir=/photos/master"}, expected: "/photos/master/2020/a.orig"},
+		{name: "Name pattern", rules: []string{"name=*.orig"}, expected: "/photos/master/2020/a.orig"},
+		{name: "Rules are evaluated in order", rules: []string{"prefer-dir=/photos/inbox", "oldest"}, expected: "/photos/inbox/d.jpg"},
+		{name: "Later rules break ties", rules: []string{"name=*.jpg", "newest"}, expected: "/photos/inbox/b.jpg"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := keeprules.Parse(tt.rules)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			// The input order must not matter.
+			for shift := range files {
+				input := append(append([]models.FileHash{}, files[shift:]...), files[:shift]...)
+				group := rules.Group(input)
+				if group.FilePath != tt.expected {
+					t.Errorf("Expected %s to be kept, got %s", tt.expected, group.FilePath)
+				}
+				if len(group.DuplicatesFound) != len(files)-1 {
+					t.Errorf("Expected %d duplicates, got %d", len(files)-1, len(group.DuplicatesFound))
+				}
+			}
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := keeprules.Parse([]string{" Oldest ", "", "name=*.orig"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(rules) != 2 || rules[0].String() != "oldest" || rules[1].String() != "name=*.orig" {
+		t.Errorf("Unexpected rules %v", rules)
+	}
+
+	for _, spec := range []string{"oldest=1", "prefer-dir", "name=", "largest"} {
+		if _, err := keeprules.Parse([]string{spec}); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
@@ -47,7 +47,7 @@ func TestFindDuplicatesInMap(t *testing.T) {
 	tracker.Start()
 
 	// ACT
-	processing.FindDuplicatesInMap(context.Background(), fileHashes, tracker)
+	processing.FindDuplicatesInMap(context.Background(), fileHashes, tracker, nil)
 
 	// ASSERT
 	fileHashes.Range(func(key, value any) bool {
@@ -62,3 +62,30 @@ func TestFindDuplicatesInMap(t *testing.T) {
 
 	tracker.Wait()
 }
+
+func TestFindDuplicatesInMapComparesSizes(t *testing.T) {
+	fileHashes := &sync.Map{}
+	for _, fh := range []models.FileHash{
+		{FileName: "a", FilePath: "/path/to/a", Hash: "abc123", FileSize: 10},
+		{FileName: "b", FilePath: "/path/to/b", Hash: "abc123", FileSize: 10},
+		{FileName: "c", FilePath: "/path/to/c", Hash: "abc123", FileSize: 20}, // hash collision
+	} {
+		fileHashes.Store(fh.FilePath, fh)
+	}
+
+	tracker := visuals.NewProgressTracker(context.Background(), reporting.NoOpReporter{}, "Test Progress")
+	tracker.Start()
+	groups := processing.FindDuplicatesInMap(context.Background(), fileHashes, tracker, nil)
+	tracker.Wait()
+
+	if groups != 1 {
+		t.Fatalf("Expected 1 group, got %d", groups)
+	}
+	fileHashes.Range(func(_, value any) bool {
+		group := value.(models.FileHash)
+		if group.FileSize != 10 || len(group.DuplicatesFound) != 1 || group.DuplicatesFound[0].FileSize != 10 {
+			t.Errorf("Expected the files of size 10 only, got %+v", group)
+		}
+		return true
+	})
+}